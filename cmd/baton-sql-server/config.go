@@ -6,12 +6,77 @@ import (
 
 var (
 	dsn = field.StringField("dsn",
-		field.WithDescription("The connection string for connecting to SQL Server"),
-		field.WithRequired(true))
+		field.WithDescription("The connection string for connecting to SQL Server"))
+	servers = field.StringSliceField("servers",
+		field.WithDescription("The SQL Server instances to sync, as name=dsn connections (e.g. prod=sqlserver://...). Used instead of --dsn"))
+	azureSQLDatabase = field.BoolField("azure-sql-database",
+		field.WithDescription("Connect to each database separately, for Azure SQL Database logical servers. The DSN should connect to master"))
 	skipUnavailableDatabases = field.BoolField("skip-unavailable-databases",
 		field.WithDescription("Skip databases that are unavailable (offline, restoring, etc)"))
+	skipSystemDatabases = field.BoolField("skip-system-databases",
+		field.WithDescription("Skip the master, model, msdb and tempdb databases"))
+	databaseIncludePatterns = field.StringSliceField("database-include-patterns",
+		field.WithDescription("Only sync databases whose name matches one of these glob patterns"))
+	databaseExcludePatterns = field.StringSliceField("database-exclude-patterns",
+		field.WithDescription("Skip databases whose name matches one of these glob patterns"))
+	syncObjectPermissions = field.BoolField("sync-object-permissions",
+		field.WithDescription("Sync tables, views, procedures and functions and the permissions granted on them"))
+	objectIncludePatterns = field.StringSliceField("object-include-patterns",
+		field.WithDescription("Only sync objects whose 'schema.object' name matches one of these glob patterns (e.g. Sales.*)"))
+	objectExcludePatterns = field.StringSliceField("object-exclude-patterns",
+		field.WithDescription("Skip objects whose 'schema.object' name matches one of these glob patterns"))
+	passwordMustChange = field.BoolField("password-must-change",
+		field.WithDescription("Require SQL logins to change their password at the next login after it is rotated"))
+	deleteKillSessions = field.BoolField("delete-kill-sessions",
+		field.WithDescription("Disable a login and kill its active sessions before deleting it"))
+	deleteDatabaseUsers = field.StringField("delete-database-users",
+		field.WithDescription("What to do with the database users of a deleted login: keep, drop or remap (to the fallback owner)"),
+		field.WithDefaultValue("keep"))
+	fallbackOwner = field.StringField("fallback-owner",
+		field.WithDescription("The login that takes ownership of the databases, jobs, schemas and roles of a deleted login"))
+	lastLoginSource = field.StringField("last-login-source",
+		field.WithDescription("Where the last login of users is read from: none, sessions, errorlog, audit or table"),
+		field.WithDefaultValue("none"))
+	lastLoginLocation = field.StringField("last-login-location",
+		field.WithDescription("The audit file path (e.g. D:\\Audit\\*.sqlaudit) or the table (e.g. dba.dbo.login_activity) the last logins are read from"))
+	systemAccountPatterns = field.StringSliceField("system-account-patterns",
+		field.WithDescription("Classify logins whose name matches one of these glob patterns as system accounts"))
+	serviceAccountPatterns = field.StringSliceField("service-account-patterns",
+		field.WithDescription("Classify logins whose name matches one of these glob patterns as service accounts (e.g. svc_*)"))
+	principalIncludePatterns = field.StringSliceField("principal-include-patterns",
+		field.WithDescription("Only sync logins, groups and server roles whose name matches one of these glob patterns"))
+	principalExcludePatterns = field.StringSliceField("principal-exclude-patterns",
+		field.WithDescription("Skip logins, groups and server roles whose name matches one of these glob patterns"))
+	excludeSystemPrincipals = field.BoolField("exclude-system-principals",
+		field.WithDescription("Skip the ##...## logins, built-in Windows accounts and fixed server roles"))
 )
 
 var cfg = field.Configuration{
-	Fields: []field.SchemaField{dsn, skipUnavailableDatabases},
+	Fields: []field.SchemaField{
+		dsn,
+		servers,
+		azureSQLDatabase,
+		skipUnavailableDatabases,
+		skipSystemDatabases,
+		databaseIncludePatterns,
+		databaseExcludePatterns,
+		syncObjectPermissions,
+		objectIncludePatterns,
+		objectExcludePatterns,
+		passwordMustChange,
+		deleteKillSessions,
+		deleteDatabaseUsers,
+		fallbackOwner,
+		lastLoginSource,
+		lastLoginLocation,
+		systemAccountPatterns,
+		serviceAccountPatterns,
+		principalIncludePatterns,
+		principalExcludePatterns,
+		excludeSystemPrincipals,
+	},
+	Constraints: []field.SchemaFieldRelationship{
+		field.FieldsAtLeastOneUsed(dsn, servers),
+		field.FieldsMutuallyExclusive(dsn, servers),
+	},
 }
@@ -53,7 +53,35 @@ func main() {
 func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, error) {
 	l := ctxzap.Extract(ctx)
 
-	cb, err := connector.New(ctx, v.GetString(dsn.FieldName), v.GetBool(skipUnavailableDatabases.FieldName))
+	serverConnections, err := connector.ParseServerConnections(v.GetStringSlice(servers.FieldName))
+	if err != nil {
+		l.Error("error parsing servers", zap.Error(err))
+		return nil, err
+	}
+
+	cb, err := connector.New(ctx, &connector.Config{
+		DSN:                      v.GetString(dsn.FieldName),
+		Servers:                  serverConnections,
+		SkipUnavailableDatabases: v.GetBool(skipUnavailableDatabases.FieldName),
+		SyncObjectPermissions:    v.GetBool(syncObjectPermissions.FieldName),
+		ObjectIncludePatterns:    v.GetStringSlice(objectIncludePatterns.FieldName),
+		ObjectExcludePatterns:    v.GetStringSlice(objectExcludePatterns.FieldName),
+		PasswordMustChange:       v.GetBool(passwordMustChange.FieldName),
+		DeleteKillSessions:       v.GetBool(deleteKillSessions.FieldName),
+		DeleteDatabaseUsers:      v.GetString(deleteDatabaseUsers.FieldName),
+		FallbackOwner:            v.GetString(fallbackOwner.FieldName),
+		LastLoginSource:          v.GetString(lastLoginSource.FieldName),
+		LastLoginLocation:        v.GetString(lastLoginLocation.FieldName),
+		SystemAccountPatterns:    v.GetStringSlice(systemAccountPatterns.FieldName),
+		ServiceAccountPatterns:   v.GetStringSlice(serviceAccountPatterns.FieldName),
+		PrincipalIncludePatterns: v.GetStringSlice(principalIncludePatterns.FieldName),
+		PrincipalExcludePatterns: v.GetStringSlice(principalExcludePatterns.FieldName),
+		ExcludeSystemPrincipals:  v.GetBool(excludeSystemPrincipals.FieldName),
+		DatabaseIncludePatterns:  v.GetStringSlice(databaseIncludePatterns.FieldName),
+		DatabaseExcludePatterns:  v.GetStringSlice(databaseExcludePatterns.FieldName),
+		SkipSystemDatabases:      v.GetBool(skipSystemDatabases.FieldName),
+		AzureSQLDatabase:         v.GetBool(azureSQLDatabase.FieldName),
+	})
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
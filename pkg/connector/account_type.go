@@ -0,0 +1,68 @@
+package connector
+
+import (
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+)
+
+// systemLoginNames are logins created by SQL Server itself.
+var systemLoginNames = map[string]bool{
+	"sa":                true,
+	"distributor_admin": true,
+}
+
+// systemLoginPrefixes are the domains of the built-in Windows accounts and service SIDs.
+var systemLoginPrefixes = []string{
+	`NT AUTHORITY\`,
+	`NT SERVICE\`,
+	`BUILTIN\`,
+}
+
+// accountTypeClassifier tells human accounts apart from service and system accounts.
+// The configured glob patterns are matched first, so they can override the built-in rules.
+type accountTypeClassifier struct {
+	systemPatterns  []string
+	servicePatterns []string
+}
+
+// classify returns the account type of a login:
+//   - system for logins created by SQL Server (sa, ##MS_...## logins), built-in Windows accounts and certificate or
+//     asymmetric key mapped logins, which only exist to sign code;
+//   - service for Windows machine and managed service accounts, whose names end with '$';
+//   - human for every other login.
+func (a *accountTypeClassifier) classify(user *mssqldb.UserModel) v2.UserTrait_AccountType {
+	if a != nil {
+		for _, pattern := range a.systemPatterns {
+			if mssqldb.MatchGlob(pattern, user.Name) {
+				return v2.UserTrait_ACCOUNT_TYPE_SYSTEM
+			}
+		}
+		for _, pattern := range a.servicePatterns {
+			if mssqldb.MatchGlob(pattern, user.Name) {
+				return v2.UserTrait_ACCOUNT_TYPE_SERVICE
+			}
+		}
+	}
+
+	name := strings.ToLower(user.Name)
+	switch {
+	case systemLoginNames[name]:
+		return v2.UserTrait_ACCOUNT_TYPE_SYSTEM
+	case strings.HasPrefix(name, "##") && strings.HasSuffix(name, "##"):
+		return v2.UserTrait_ACCOUNT_TYPE_SYSTEM
+	case user.Type == "CERTIFICATE_MAPPED_LOGIN" || user.Type == "ASYMMETRIC_KEY_MAPPED_LOGIN":
+		return v2.UserTrait_ACCOUNT_TYPE_SYSTEM
+	case strings.HasSuffix(name, "$"):
+		return v2.UserTrait_ACCOUNT_TYPE_SERVICE
+	}
+
+	for _, prefix := range systemLoginPrefixes {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) {
+			return v2.UserTrait_ACCOUNT_TYPE_SYSTEM
+		}
+	}
+
+	return v2.UserTrait_ACCOUNT_TYPE_HUMAN
+}
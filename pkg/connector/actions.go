@@ -0,0 +1,133 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// actionHandler runs an action with the arguments it was invoked with and returns the action's response.
+type actionHandler func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error)
+
+type action struct {
+	schema  *v2.BatonActionSchema
+	handler actionHandler
+}
+
+// actionManager runs the connector's custom actions. Actions run to completion when they are invoked,
+// so there is no status to track afterwards.
+type actionManager struct {
+	actions map[string]*action
+}
+
+func (m *actionManager) register(schema *v2.BatonActionSchema, handler actionHandler) {
+	m.actions[schema.Name] = &action{schema: schema, handler: handler}
+}
+
+func (m *actionManager) ListActionSchemas(ctx context.Context) ([]*v2.BatonActionSchema, annotations.Annotations, error) {
+	ret := make([]*v2.BatonActionSchema, 0, len(m.actions))
+	for _, a := range m.actions {
+		ret = append(ret, a.schema)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret, nil, nil
+}
+
+func (m *actionManager) GetActionSchema(ctx context.Context, name string) (*v2.BatonActionSchema, annotations.Annotations, error) {
+	a, ok := m.actions[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("action %s not found", name)
+	}
+
+	return a.schema, nil, nil
+}
+
+func (m *actionManager) InvokeAction(ctx context.Context, name string, args *structpb.Struct) (string, v2.BatonActionStatus, *structpb.Struct, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	a, ok := m.actions[name]
+	if !ok {
+		return "", v2.BatonActionStatus_BATON_ACTION_STATUS_UNSPECIFIED, nil, nil, fmt.Errorf("action %s not found", name)
+	}
+
+	id := uuid.NewString()
+	l.Debug("invoking action", zap.String("action", name), zap.String("id", id))
+
+	resp, err := a.handler(ctx, args)
+	if err != nil {
+		return id, v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, err
+	}
+
+	return id, v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, resp, nil, nil
+}
+
+func (m *actionManager) GetActionStatus(ctx context.Context, id string) (v2.BatonActionStatus, string, *structpb.Struct, annotations.Annotations, error) {
+	return v2.BatonActionStatus_BATON_ACTION_STATUS_UNKNOWN, "", nil, nil, fmt.Errorf("actions complete when they are invoked, the status of action %s is not tracked", id)
+}
+
+// stringActionField returns the schema of a string argument of an action.
+func stringActionField(name, displayName, description string, required bool) *config.Field {
+	return &config.Field{
+		Name:        name,
+		DisplayName: displayName,
+		Description: description,
+		IsRequired:  required,
+		Field:       &config.Field_StringField{StringField: &config.StringField{}},
+	}
+}
+
+// boolActionField returns the schema of a boolean argument or return value of an action.
+func boolActionField(name, displayName, description string) *config.Field {
+	return &config.Field{
+		Name:        name,
+		DisplayName: displayName,
+		Description: description,
+		Field:       &config.Field_BoolField{BoolField: &config.BoolField{}},
+	}
+}
+
+// actionStringArg returns the value of a string argument, or an error if a required argument is missing.
+func actionStringArg(args *structpb.Struct, name string, required bool) (string, error) {
+	v := args.GetFields()[name].GetStringValue()
+	if v == "" && required {
+		return "", fmt.Errorf("missing required argument %s", name)
+	}
+
+	return v, nil
+}
+
+// actionSuccess is the response of actions that do not return anything but their outcome.
+func actionSuccess() *structpb.Struct {
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			"success": structpb.NewBoolValue(true),
+		},
+	}
+}
+
+var actionSuccessReturnTypes = []*config.Field{
+	boolActionField("success", "Success", "Whether the action succeeded."),
+}
+
+func newActionManager(c *mssqldb.Client) *actionManager {
+	m := &actionManager{
+		actions: make(map[string]*action),
+	}
+
+	registerOrphanedUserActions(m, c)
+	registerLoginActions(m, c)
+
+	return m
+}
@@ -0,0 +1,389 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+var (
+	_ connectorbuilder.ResourceProvisionerV2 = (*applicationRoleSyncer)(nil)
+	_ connectorbuilder.ResourceManager       = (*applicationRoleSyncer)(nil)
+	_ connectorbuilder.CredentialManager     = (*applicationRoleSyncer)(nil)
+)
+
+// applicationRoleSyncer implements ResourceSyncer, ResourceProvisionerV2, ResourceManager and CredentialManager for application roles.
+// Permissions and role memberships granted to an application role are synced as grants on the securable,
+// and the permissions granted on the application role itself as grants on the application role.
+type applicationRoleSyncer struct {
+	resourceType *v2.ResourceType
+	client       *mssqldb.Client
+}
+
+func (d *applicationRoleSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
+	return d.resourceType
+}
+
+func (d *applicationRoleSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	if parentResourceID.ResourceType != resourceTypeDatabase.Id {
+		return nil, "", nil, fmt.Errorf("application roles must have a database as the parent resource")
+	}
+
+	db, err := d.parentDatabase(ctx, parentResourceID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	appRoles, nextPageToken, err := d.client.ListApplicationRoles(ctx, db.Name, &mssqldb.Pager{Token: pToken.Token, Size: pToken.Size})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var ret []*v2.Resource
+	for _, appRoleModel := range appRoles {
+		r, err := d.applicationRoleResource(ctx, db.Name, appRoleModel, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *applicationRoleSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var ret []*v2.Entitlement
+
+	for key, slug := range databaseRolePermissions {
+		// Application roles have no owner, so TAKE OWNERSHIP cannot be granted on them.
+		if key == "TO" {
+			continue
+		}
+
+		name := mssqldb.DatabasePermissions[key]
+		ret = append(ret,
+			enTypes.NewPermissionEntitlement(resource, slug,
+				enTypes.WithDisplayName(name),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+			enTypes.NewPermissionEntitlement(resource, slug+permissionGrantOptionSuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (With Grant)", name)),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+			enTypes.NewPermissionEntitlement(resource, slug+permissionDenySuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (Deny)", name)),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+		)
+	}
+
+	return ret, "", nil, nil
+}
+
+func (d *applicationRoleSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	dbName, appRoleID, err := parseApplicationRoleResourceID(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	principalPerms, nextPageToken, err := d.client.ListDatabasePrincipalPermissions(
+		ctx,
+		dbName,
+		appRoleID,
+		&mssqldb.Pager{Token: pToken.Token, Size: pToken.Size},
+	)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var ret []*v2.Grant
+	for _, p := range principalPerms {
+		rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
+		if err != nil {
+			l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+			continue
+		}
+
+		principalID, err := databasePrincipalResourceID(ctx, d.client, dbName, p.PrincipalID, p.PrincipalName, rt)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if principalID == nil {
+			continue
+		}
+
+		for _, perm := range strings.Split(p.Permissions, ",") {
+			permSlug, ok := databaseRolePermissions[strings.TrimSpace(perm)]
+			if !ok {
+				continue
+			}
+
+			slug, ok := permissionSlug(permSlug, p.State)
+			if !ok {
+				continue
+			}
+			ret = append(ret, grTypes.NewGrant(resource, slug, principalID))
+		}
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+// Grant grants a permission on the application role, e.g. ALTER ON APPLICATION ROLE::[reporting].
+func (d *applicationRoleSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if resource.Id.ResourceType != resourceTypeUser.Id {
+		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+	}
+
+	// application-role:baton_test:7:alter-grant
+	splitId := strings.Split(entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	permission, state, err := parseDatabaseRolePermissionSlug(splitId[3])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appRole, err := d.client.GetApplicationRole(ctx, dbName, splitId[2])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ensureDatabaseUser(ctx, d.client, dbName, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state == permissionStateDeny {
+		err = d.client.DenyPermissionOnDatabasePrincipal(ctx, permission, dbName, mssqldb.DatabasePrincipalClassApplicationRole, appRole.Name, user.Name)
+	} else {
+		err = d.client.GrantPermissionOnDatabasePrincipal(
+			ctx, permission, dbName, mssqldb.DatabasePrincipalClassApplicationRole, appRole.Name, user.Name, state == permissionStateGrantWithGrant,
+		)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grTypes.NewGrant(entitlement.Resource, splitId[3], &v2.ResourceId{
+		Resource:     user.ID,
+		ResourceType: resourceTypeUser.Id,
+	})
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+// Revoke revokes a granted or denied permission on the application role.
+func (d *applicationRoleSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	if grant.Principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("resource type %s is not supported for revoking", grant.Principal.Id.ResourceType)
+	}
+
+	// application-role:baton_test:7:alter-grant
+	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	permission, state, err := parseDatabaseRolePermissionSlug(splitId[3])
+	if err != nil {
+		return nil, err
+	}
+
+	appRole, err := d.client.GetApplicationRole(ctx, dbName, splitId[2])
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, grant.Principal.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.client.RevokePermissionOnDatabasePrincipal(
+		ctx, permission, dbName, mssqldb.DatabasePrincipalClassApplicationRole, appRole.Name, user.Name, state == permissionStateGrantWithGrant,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Create creates an application role with a random password in the parent database.
+// The password is not returned, rotate the credential to obtain one.
+func (d *applicationRoleSyncer) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	parentResourceID := resource.GetParentResourceId()
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeDatabase.Id {
+		return nil, nil, fmt.Errorf("application roles must have a database as the parent resource")
+	}
+
+	name := resource.GetDisplayName()
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing application role name")
+	}
+
+	db, err := d.parentDatabase(ctx, parentResourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = d.client.CreateApplicationRole(ctx, db.Name, name, generateStrongPassword(defaultPasswordLength))
+	if err != nil {
+		l.Error("failed to create application role", zap.Error(err), zap.String("name", name), zap.String("database", db.Name))
+		return nil, nil, fmt.Errorf("failed to create application role: %w", err)
+	}
+
+	appRole, err := d.client.GetApplicationRoleByName(ctx, db.Name, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get application role: %w", err)
+	}
+
+	r, err := d.applicationRoleResource(ctx, db.Name, appRole, parentResourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, nil, nil
+}
+
+func (d *applicationRoleSyncer) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	dbName, appRoleID, err := parseApplicationRoleResourceID(resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	appRole, err := d.client.GetApplicationRole(ctx, dbName, appRoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.client.DropApplicationRole(ctx, dbName, appRole.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Rotate sets a new random password on the application role and returns it.
+func (d *applicationRoleSyncer) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	length, err := randomPasswordLength(credentialOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbName, appRoleID, err := parseApplicationRoleResourceID(resourceId.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	appRole, err := d.client.GetApplicationRole(ctx, dbName, appRoleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	password := generateStrongPassword(length)
+	err = d.client.SetApplicationRolePassword(ctx, dbName, appRole.Name, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to rotate application role password: %w", err)
+	}
+
+	plaintextData := []*v2.PlaintextData{
+		{
+			Name:        "password",
+			Description: "The new password for the application role",
+			Schema:      "text/plain",
+			Bytes:       []byte(password),
+		},
+	}
+
+	return plaintextData, nil, nil
+}
+
+func (d *applicationRoleSyncer) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}, nil, nil
+}
+
+func (d *applicationRoleSyncer) parentDatabase(ctx context.Context, parentResourceID *v2.ResourceId) (*mssqldb.DbModel, error) {
+	dbID, err := strconv.ParseInt(parentResourceID.Resource, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.client.GetDatabase(ctx, dbID)
+}
+
+func (d *applicationRoleSyncer) applicationRoleResource(
+	ctx context.Context,
+	dbName string,
+	appRole *mssqldb.ApplicationRoleModel,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	opts := []resource.ResourceOption{resource.WithParentResourceID(parentResourceID)}
+	if appRole.DefaultSchema != "" {
+		opts = append(opts, resource.WithDescription(fmt.Sprintf("Default schema: %s", appRole.DefaultSchema)))
+	}
+
+	return resource.NewResource(
+		fmt.Sprintf("%s (%s)", appRole.Name, dbName),
+		d.ResourceType(ctx),
+		fmt.Sprintf("%s:%d", dbName, appRole.ID),
+		opts...,
+	)
+}
+
+// parseApplicationRoleResourceID splits an application role resource ID into the database name and principal ID.
+func parseApplicationRoleResourceID(id string) (string, string, error) {
+	idParts := strings.Split(id, ":")
+	if len(idParts) != 2 {
+		return "", "", fmt.Errorf("invalid application role id: %s", id)
+	}
+
+	return idParts[0], idParts[1], nil
+}
+
+func newApplicationRoleSyncer(ctx context.Context, c *mssqldb.Client) *applicationRoleSyncer {
+	return &applicationRoleSyncer{
+		resourceType: resourceTypeApplicationRole,
+		client:       c,
+	}
+}
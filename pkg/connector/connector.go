@@ -12,7 +12,67 @@ import (
 )
 
 type Mssqldb struct {
+	servers []*serverConnection
+	// multiServer qualifies resource IDs with the server name and routes calls to the server they belong to.
+	multiServer bool
+	// routers route the syncers of each resource type to every server in multi-server mode.
+	routers               []connectorbuilder.ResourceSyncer
+	syncObjectPermissions bool
+	objectPatterns        *mssqldb.NamePatterns
+	passwordMustChange    bool
+	deleteOptions         *loginDeleteOptions
+	accountTypes          *accountTypeClassifier
+}
+
+// serverConnection is a SQL Server instance synced by the connector.
+type serverConnection struct {
+	name   string
 	client *mssqldb.Client
+	// lastLogins is kept per server, as the same login name can exist on several servers.
+	lastLogins *lastLogins
+}
+
+// Config holds the options used to build the connector.
+type Config struct {
+	DSN string
+	// Servers are the named SQL Server instances synced in multi-server mode, used instead of the DSN.
+	Servers                  []ServerConnection
+	SkipUnavailableDatabases bool
+	// SyncObjectPermissions enables syncing tables, views and routines along with the permissions granted on them.
+	SyncObjectPermissions bool
+	// ObjectIncludePatterns and ObjectExcludePatterns are glob patterns matched against 'schema.object' names.
+	ObjectIncludePatterns []string
+	ObjectExcludePatterns []string
+	// PasswordMustChange makes SQL logins change their password at the next login after it is rotated.
+	PasswordMustChange bool
+	// DeleteKillSessions disables a login and kills its sessions before it is deleted.
+	DeleteKillSessions bool
+	// DeleteDatabaseUsers is what happens to the database users of a deleted login: keep, drop or remap.
+	DeleteDatabaseUsers string
+	// FallbackOwner is the login that takes ownership of the databases and jobs of a deleted login,
+	// and the login database users are re-mapped to.
+	FallbackOwner string
+	// LastLoginSource is where the last login of users is read from: none, sessions, errorlog, audit or table.
+	LastLoginSource string
+	// LastLoginLocation is the audit file path or the table name read by the audit and table sources.
+	LastLoginLocation string
+	// SystemAccountPatterns and ServiceAccountPatterns are glob patterns matched against login names to classify
+	// them as system or service accounts, before the built-in rules.
+	SystemAccountPatterns  []string
+	ServiceAccountPatterns []string
+	// PrincipalIncludePatterns and PrincipalExcludePatterns are glob patterns matched against the names of logins,
+	// groups and server roles. Excluded principals are not synced, granted or provisioned.
+	PrincipalIncludePatterns []string
+	PrincipalExcludePatterns []string
+	// ExcludeSystemPrincipals excludes the principals created by SQL Server.
+	ExcludeSystemPrincipals bool
+	// DatabaseIncludePatterns and DatabaseExcludePatterns are glob patterns matched against database names.
+	DatabaseIncludePatterns []string
+	DatabaseExcludePatterns []string
+	// SkipSystemDatabases skips master, model, msdb and tempdb.
+	SkipSystemDatabases bool
+	// AzureSQLDatabase connects to each database separately, for Azure SQL Database logical servers.
+	AzureSQLDatabase bool
 }
 
 // Resource model:
@@ -21,18 +81,25 @@ type Mssqldb struct {
 //    |-- Permissions
 // |-- Databases
 //    |-- Principals
-//    |-- Users
+//    |-- Users (without a server login)
+//    |-- Application Roles
+//    |-- Schemas
+//       |-- Tables, Views, Routines (optional)
 
 func (o *Mssqldb) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	var annos annotations.Annotations
 
-	serverInfo, err := o.client.GetServer(ctx)
-	if err != nil {
-		return nil, err
+	displayName := fmt.Sprintf("Microsoft SQL Server (%d servers)", len(o.servers))
+	if !o.multiServer {
+		serverInfo, err := o.servers[0].client.GetServer(ctx)
+		if err != nil {
+			return nil, err
+		}
+		displayName = fmt.Sprintf("Microsoft SQL Server (%s)", serverInfo.Name)
 	}
 
-	return &v2.ConnectorMetadata{
-		DisplayName: fmt.Sprintf("Microsoft SQL Server (%s)", serverInfo.Name),
+	md := &v2.ConnectorMetadata{
+		DisplayName: displayName,
 		Annotations: annos,
 		Description: "Baton connector for Microsoft SQL Server connector",
 		AccountCreationSchema: &v2.ConnectorAccountCreationSchema{
@@ -40,7 +107,7 @@ func (o *Mssqldb) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 				"login_type": {
 					DisplayName: "Login Type",
 					Required:    true,
-					Description: "The type of SQL Server authentication to use (WINDOWS, SQL, AZURE_AD, or ENTRA_ID).",
+					Description: "The type of SQL Server authentication to use (WINDOWS, SQL, AZURE_AD, ENTRA_ID, CERTIFICATE or ASYMMETRIC_KEY).",
 					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
 						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
 					},
@@ -67,37 +134,284 @@ func (o *Mssqldb) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 					Placeholder: "username",
 					Order:       3,
 				},
+				"default_database": {
+					DisplayName: "Default Database",
+					Required:    false,
+					Description: "The database the login connects to by default. Not used for external provider, certificate and asymmetric key logins.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Placeholder: "master",
+					Order:       4,
+				},
+				"default_language": {
+					DisplayName: "Default Language",
+					Required:    false,
+					Description: "The default language of the login. Not used for external provider, certificate and asymmetric key logins.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Placeholder: "us_english",
+					Order:       5,
+				},
+				"check_policy": {
+					DisplayName: "Enforce Password Policy",
+					Required:    false,
+					Description: "Apply the Windows password policy to the login. Only used for SQL Server authentication.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_BoolField{
+						BoolField: &v2.ConnectorAccountCreationSchema_BoolField{},
+					},
+					Order: 6,
+				},
+				"check_expiration": {
+					DisplayName: "Enforce Password Expiration",
+					Required:    false,
+					Description: "Apply the password expiration policy to the login. Only used for SQL Server authentication.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_BoolField{
+						BoolField: &v2.ConnectorAccountCreationSchema_BoolField{},
+					},
+					Order: 7,
+				},
+				"must_change": {
+					DisplayName: "User Must Change Password",
+					Required:    false,
+					Description: "Require the user to change the password at the first login. Turns on the password policy and expiration. Only used for SQL Server authentication.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_BoolField{
+						BoolField: &v2.ConnectorAccountCreationSchema_BoolField{},
+					},
+					Order: 8,
+				},
+				"certificate": {
+					DisplayName: "Certificate",
+					Required:    false,
+					Description: "The certificate in master to map the login to. Only used for CERTIFICATE logins.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Order: 9,
+				},
+				"asymmetric_key": {
+					DisplayName: "Asymmetric Key",
+					Required:    false,
+					Description: "The asymmetric key in master to map the login to. Only used for ASYMMETRIC_KEY logins.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Order: 10,
+				},
+				"databases": {
+					DisplayName: "Databases",
+					Required:    false,
+					Description: "The databases to create a user for the login in.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringListField{
+						StringListField: &v2.ConnectorAccountCreationSchema_StringListField{},
+					},
+					Order: 11,
+				},
+				"default_schema": {
+					DisplayName: "Default Schema",
+					Required:    false,
+					Description: "The default schema of the database users created for the login.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+						StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+					},
+					Placeholder: "dbo",
+					Order:       12,
+				},
+				"database_roles": {
+					DisplayName: "Database Roles",
+					Required:    false,
+					Description: "The database roles to add the database users created for the login to, in each of the databases.",
+					Field: &v2.ConnectorAccountCreationSchema_Field_StringListField{
+						StringListField: &v2.ConnectorAccountCreationSchema_StringListField{},
+					},
+					Placeholder: "db_datareader",
+					Order:       13,
+				},
 			},
 		},
-	}, nil
+	}
+
+	if o.multiServer {
+		md.AccountCreationSchema.FieldMap[serverArgName] = &v2.ConnectorAccountCreationSchema_Field{
+			DisplayName: "Server",
+			Required:    true,
+			Description: "The name of the server to create the login on.",
+			Field: &v2.ConnectorAccountCreationSchema_Field_StringField{
+				StringField: &v2.ConnectorAccountCreationSchema_StringField{},
+			},
+			Order: 14,
+		}
+	}
+
+	return md, nil
 }
 
 func (o *Mssqldb) Validate(ctx context.Context) (annotations.Annotations, error) {
-	_, err := o.client.GetServer(ctx)
-	if err != nil {
-		return nil, err
+	for _, s := range o.servers {
+		_, err := s.client.GetServer(ctx)
+		if err != nil {
+			if o.multiServer {
+				return nil, fmt.Errorf("server %s: %w", s.name, err)
+			}
+			return nil, err
+		}
 	}
 
 	return nil, nil
 }
 
 func (o *Mssqldb) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		newServerSyncer(ctx, o.client),
-		newDatabaseSyncer(ctx, o.client),
-		newUserPrincipalSyncer(ctx, o.client),
-		newServerRolePrincipalSyncer(ctx, o.client),
-		newDatabaseRolePrincipalSyncer(ctx, o.client),
-		newGroupPrincipalSyncer(ctx, o.client),
+	if !o.multiServer {
+		return o.serverSyncers(ctx, o.servers[0])
+	}
+
+	return o.routers
+}
+
+// routeSyncers returns a router for the syncers of each resource type of the servers. It is called when the connector
+// is created, so a syncer whose capabilities cannot be routed fails the connector instead of being left out of the sync.
+func (o *Mssqldb) routeSyncers(ctx context.Context) ([]connectorbuilder.ResourceSyncer, error) {
+	// Every server has the same syncers, so the syncers of each resource type are at the same position.
+	names := make([]string, 0, len(o.servers))
+	perServer := make(map[string][]connectorbuilder.ResourceSyncer, len(o.servers))
+	for _, s := range o.servers {
+		names = append(names, s.name)
+		perServer[s.name] = o.serverSyncers(ctx, s)
+	}
+
+	var ret []connectorbuilder.ResourceSyncer
+	for i := range perServer[names[0]] {
+		syncers := make(map[string]connectorbuilder.ResourceSyncer, len(names))
+		for _, name := range names {
+			syncers[name] = perServer[name][i]
+		}
+
+		router, err := newServerRouter(names, syncers)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, router)
+	}
+
+	return ret, nil
+}
+
+// serverSyncers returns the syncers of a single server.
+func (o *Mssqldb) serverSyncers(ctx context.Context, s *serverConnection) []connectorbuilder.ResourceSyncer {
+	syncers := []connectorbuilder.ResourceSyncer{
+		newServerSyncer(ctx, s.client),
+		newDatabaseSyncer(ctx, s.client),
+		newUserPrincipalSyncer(ctx, s.client, o.passwordMustChange, o.deleteOptions, s.lastLogins, o.accountTypes),
+		newServerRolePrincipalSyncer(ctx, s.client),
+		newDatabaseRolePrincipalSyncer(ctx, s.client),
+		newDatabaseUserSyncer(ctx, s.client),
+		newApplicationRoleSyncer(ctx, s.client),
+		newSchemaSyncer(ctx, s.client, o.syncObjectPermissions),
+		newGroupPrincipalSyncer(ctx, s.client),
+	}
+
+	if o.syncObjectPermissions {
+		syncers = append(syncers,
+			newTableSyncer(ctx, s.client, o.objectPatterns),
+			newViewSyncer(ctx, s.client, o.objectPatterns),
+			newRoutineSyncer(ctx, s.client, o.objectPatterns),
+		)
+	}
+
+	return syncers
+}
+
+func (o *Mssqldb) RegisterActionManager(ctx context.Context) (connectorbuilder.CustomActionManager, error) {
+	if !o.multiServer {
+		return newActionManager(o.servers[0].client), nil
 	}
+
+	names := make([]string, 0, len(o.servers))
+	managers := make(map[string]*actionManager, len(o.servers))
+	for _, s := range o.servers {
+		names = append(names, s.name)
+		managers[s.name] = newActionManager(s.client)
+	}
+
+	return newServerActionManager(names, managers), nil
 }
 
-func New(ctx context.Context, dsn string, skipUnavailableDatabases bool) (*Mssqldb, error) {
-	c, err := mssqldb.New(ctx, dsn, skipUnavailableDatabases)
+func New(ctx context.Context, cfg *Config) (*Mssqldb, error) {
+	deleteOptions, err := newLoginDeleteOptions(cfg.DeleteKillSessions, cfg.DeleteDatabaseUsers, cfg.FallbackOwner)
 	if err != nil {
 		return nil, err
 	}
-	return &Mssqldb{
-		client: c,
-	}, nil
+
+	connections := cfg.Servers
+	multiServer := len(connections) > 0
+	if !multiServer {
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("a dsn or at least one server is required")
+		}
+		connections = []ServerConnection{{DSN: cfg.DSN}}
+	}
+
+	opts := []mssqldb.Option{
+		mssqldb.WithPrincipalFilter(&mssqldb.PrincipalFilter{
+			Include:       cfg.PrincipalIncludePatterns,
+			Exclude:       cfg.PrincipalExcludePatterns,
+			ExcludeSystem: cfg.ExcludeSystemPrincipals,
+		}),
+		mssqldb.WithDatabaseFilter(&mssqldb.DatabaseFilter{
+			Include:       cfg.DatabaseIncludePatterns,
+			Exclude:       cfg.DatabaseExcludePatterns,
+			ExcludeSystem: cfg.SkipSystemDatabases,
+		}),
+	}
+	if cfg.AzureSQLDatabase {
+		opts = append(opts, mssqldb.WithAzureSQLDatabase())
+	}
+
+	servers := make([]*serverConnection, 0, len(connections))
+	for _, conn := range connections {
+		lastLogins, err := newLastLogins(cfg.LastLoginSource, cfg.LastLoginLocation)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := mssqldb.New(ctx, conn.DSN, cfg.SkipUnavailableDatabases, opts...)
+		if err != nil {
+			if multiServer {
+				return nil, fmt.Errorf("server %s: %w", conn.Name, err)
+			}
+			return nil, err
+		}
+
+		servers = append(servers, &serverConnection{
+			name:       conn.Name,
+			client:     c,
+			lastLogins: lastLogins,
+		})
+	}
+
+	o := &Mssqldb{
+		servers:               servers,
+		multiServer:           multiServer,
+		syncObjectPermissions: cfg.SyncObjectPermissions,
+		objectPatterns: &mssqldb.NamePatterns{
+			Include: cfg.ObjectIncludePatterns,
+			Exclude: cfg.ObjectExcludePatterns,
+		},
+		passwordMustChange: cfg.PasswordMustChange,
+		deleteOptions:      deleteOptions,
+		accountTypes: &accountTypeClassifier{
+			systemPatterns:  cfg.SystemAccountPatterns,
+			servicePatterns: cfg.ServiceAccountPatterns,
+		},
+	}
+
+	if multiServer {
+		o.routers, err = o.routeSyncers(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
 }
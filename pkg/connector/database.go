@@ -49,7 +49,9 @@ func (d *databaseSyncer) List(ctx context.Context, parentResourceID *v2.Resource
 			d.ResourceType(ctx),
 			dbModel.ID,
 			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeDatabaseRole.Id}),
-			// resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeDatabaseUser.Id}),
+			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeSchema.Id}),
+			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeApplicationRole.Id}),
+			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: resourceTypeDatabaseUser.Id}),
 		)
 		if err != nil {
 			return nil, "", nil, err
@@ -63,8 +65,10 @@ func (d *databaseSyncer) List(ctx context.Context, parentResourceID *v2.Resource
 func (d *databaseSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var ret []*v2.Entitlement
 
+	ret = append(ret, ownerEntitlement(resource))
 	for key, name := range mssqldb.DatabasePermissions {
 		grantSlug := fmt.Sprintf("%s (With Grant)", name)
+		denySlug := fmt.Sprintf("%s (Deny)", name)
 		ret = append(ret,
 			&v2.Entitlement{
 				Id:          enTypes.NewEntitlementID(resource, key),
@@ -81,6 +85,14 @@ func (d *databaseSyncer) Entitlements(ctx context.Context, resource *v2.Resource
 				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
 				Resource:    resource,
 				GrantableTo: []*v2.ResourceType{resourceTypeUser},
+			},
+			&v2.Entitlement{
+				Id:          enTypes.NewEntitlementID(resource, key+"-deny"),
+				DisplayName: denySlug,
+				Slug:        denySlug,
+				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+				Resource:    resource,
+				GrantableTo: []*v2.ResourceType{resourceTypeUser},
 			})
 	}
 
@@ -101,6 +113,22 @@ func (d *databaseSyncer) Grants(ctx context.Context, resource *v2.Resource, pTok
 		return nil, "", nil, err
 	}
 
+	if pToken.Token == "" {
+		owner, err := d.client.GetDatabaseOwner(ctx, dbID)
+		if err != nil && !errors.Is(err, mssqldb.ErrNoOwner) {
+			return nil, "", nil, err
+		}
+		if owner != nil {
+			ownerGrant, err := serverOwnerGrant(ctx, d.client, resource, owner)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if ownerGrant != nil {
+				ret = append(ret, ownerGrant)
+			}
+		}
+	}
+
 	principalPerms, nextPageToken, err := d.client.ListDatabasePermissions(ctx, db.Name, &mssqldb.Pager{Size: pToken.Size, Token: pToken.Token})
 	if err != nil {
 		return nil, "", nil, err
@@ -110,50 +138,31 @@ func (d *databaseSyncer) Grants(ctx context.Context, resource *v2.Resource, pTok
 		perms := strings.Split(p.Permissions, ",")
 		for _, perm := range perms {
 			perm = strings.TrimSpace(perm)
-			if _, ok := mssqldb.DatabasePermissions[perm]; ok {
-				rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
-				if err != nil {
-					l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
-					continue
-				}
-
-				var resourceID *v2.ResourceId
-				switch rt.Id {
-				case resourceTypeUser.Id, resourceTypeGroup.Id:
-					serverPrincipal, err := d.client.GetServerPrincipalForDatabasePrincipal(ctx, db.Name, p.PrincipalID)
-					if err != nil {
-						if errors.Is(err, mssqldb.ErrNoServerPrincipal) {
-							l.Debug("no server principal for database principal", zap.String("user", p.PrincipalName))
-							continue
-						}
-						return nil, "", nil, err
-					}
-
-					resourceID = &v2.ResourceId{
-						ResourceType: rt.Id,
-						Resource:     serverPrincipal.ID,
-					}
-
-				case resourceTypeDatabaseRole.Id:
-					resourceID = &v2.ResourceId{
-						ResourceType: rt.Id,
-						Resource:     fmt.Sprintf("%s:%d", db.Name, p.PrincipalID),
-					}
-				default:
-					return nil, "", nil, fmt.Errorf("unexpected resource type: %s", rt.Id)
-				}
-
-				switch p.State {
-				case "G":
-					ret = append(ret, grTypes.NewGrant(resource, perm, &v2.Resource{
-						Id: resourceID,
-					}))
-				case "W":
-					ret = append(ret, grTypes.NewGrant(resource, perm+"-grant", &v2.Resource{
-						Id: resourceID,
-					}))
-				}
+			if _, ok := mssqldb.DatabasePermissions[perm]; !ok {
+				continue
+			}
+
+			rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
+			if err != nil {
+				l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+				continue
+			}
+
+			resourceID, err := databasePrincipalResourceID(ctx, d.client, db.Name, p.PrincipalID, p.PrincipalName, rt)
+			if err != nil {
+				return nil, "", nil, err
 			}
+			if resourceID == nil {
+				continue
+			}
+
+			slug, ok := permissionSlug(perm, p.State)
+			if !ok {
+				continue
+			}
+			ret = append(ret, grTypes.NewGrant(resource, slug, &v2.Resource{
+				Id: resourceID,
+			}))
 		}
 	}
 
@@ -161,8 +170,6 @@ func (d *databaseSyncer) Grants(ctx context.Context, resource *v2.Resource, pTok
 }
 
 func (d *databaseSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
-	l := ctxzap.Extract(ctx)
-
 	if resource.Id.ResourceType != resourceTypeUser.Id {
 		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
 	}
@@ -177,11 +184,7 @@ func (d *databaseSyncer) Grant(ctx context.Context, resource *v2.Resource, entit
 		return nil, nil, fmt.Errorf("unexpected database id: %s", splitId[1])
 	}
 
-	permission := splitId[2]
-	isGrant := strings.Contains(permission, "-grant")
-	if isGrant {
-		permission = strings.Replace(permission, "-grant", "", 1)
-	}
+	permission, state := parsePermissionSlug(splitId[2])
 
 	database, err := d.client.GetDatabase(ctx, dbId)
 	if err != nil {
@@ -193,31 +196,31 @@ func (d *databaseSyncer) Grant(ctx context.Context, resource *v2.Resource, entit
 		return nil, nil, err
 	}
 
-	dbUser, err := d.client.GetUserFromDb(ctx, database.Name, resource.Id.Resource)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if dbUser == nil {
-		l.Info("user not found in database, creating user for principal", zap.String("user", resource.Id.Resource))
-
-		err = d.client.CreateDatabaseUserForPrincipal(ctx, database.Name, user.Name)
+	// The new owner of a database must not have a user in it, so ownership is changed before any user is created.
+	if permission == ownerSlug {
+		err = d.client.ChangeDatabaseOwner(ctx, database.Name, user.Name)
 		if err != nil {
 			return nil, nil, err
 		}
+
+		return []*v2.Grant{grTypes.NewGrant(entitlement.Resource, ownerSlug, resource.Id)}, nil, nil
 	}
 
-	err = d.client.GrantPermissionOnDatabase(ctx, permission, database.Name, user.Name)
+	err = ensureDatabaseUser(ctx, d.client, database.Name, user)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	entitlementName := permission
-	if isGrant {
-		entitlementName = permission + "-grant"
+	if state == permissionStateDeny {
+		err = d.client.DenyPermissionOnDatabase(ctx, permission, database.Name, user.Name)
+	} else {
+		err = d.client.GrantPermissionOnDatabase(ctx, permission, database.Name, user.Name, state == permissionStateGrantWithGrant)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 
-	newGrant := grTypes.NewGrant(resource, entitlementName, &v2.ResourceId{
+	newGrant := grTypes.NewGrant(entitlement.Resource, splitId[2], &v2.ResourceId{
 		Resource:     user.ID,
 		ResourceType: resourceTypeUser.Id,
 	})
@@ -233,13 +236,21 @@ func (d *databaseSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotatio
 	}
 
 	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) != 3 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
 
 	dbId, err := strconv.ParseInt(splitId[1], 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("unexpected database id: %s", splitId[1])
 	}
 
-	permission := strings.Replace(splitId[2], "-grant", "", 1)
+	if splitId[2] == ownerSlug {
+		return nil, errRevokeOwner
+	}
+
+	// REVOKE removes both granted and denied permissions.
+	permission, state := parsePermissionSlug(splitId[2])
 
 	database, err := d.client.GetDatabase(ctx, dbId)
 	if err != nil {
@@ -251,7 +262,7 @@ func (d *databaseSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotatio
 		return nil, err
 	}
 
-	err = d.client.RevokePermissionOnDatabase(ctx, permission, database.Name, user.Name)
+	err = d.client.RevokePermissionOnDatabase(ctx, permission, database.Name, user.Name, state == permissionStateGrantWithGrant)
 	if err != nil {
 		return nil, err
 	}
@@ -260,6 +271,87 @@ func (d *databaseSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotatio
 	return nil, nil
 }
 
+// databasePrincipalResourceID returns the resource ID for a database principal that has been granted a permission.
+// Users and groups are resolved to their server principal, or to a database user if there is no server principal.
+// It returns nil for principals excluded by the principal filter.
+func databasePrincipalResourceID(
+	ctx context.Context,
+	c *mssqldb.Client,
+	dbName string,
+	principalID int64,
+	principalName string,
+	rt *v2.ResourceType,
+) (*v2.ResourceId, error) {
+	l := ctxzap.Extract(ctx)
+
+	switch rt.Id {
+	case resourceTypeUser.Id, resourceTypeGroup.Id:
+		serverPrincipal, err := c.GetServerPrincipalForDatabasePrincipal(ctx, dbName, principalID)
+		if err != nil {
+			if errors.Is(err, mssqldb.ErrNoServerPrincipal) {
+				l.Debug("no server principal for database principal, using database user", zap.String("user", principalName))
+				return &v2.ResourceId{
+					ResourceType: resourceTypeDatabaseUser.Id,
+					Resource:     fmt.Sprintf("%s:%d", dbName, principalID),
+				}, nil
+			}
+			return nil, err
+		}
+		if !c.PrincipalAllowed(serverPrincipal.Name) {
+			return nil, nil
+		}
+
+		return &v2.ResourceId{
+			ResourceType: rt.Id,
+			Resource:     serverPrincipal.ID,
+		}, nil
+
+	case resourceTypeDatabaseRole.Id, resourceTypeApplicationRole.Id:
+		return &v2.ResourceId{
+			ResourceType: rt.Id,
+			Resource:     fmt.Sprintf("%s:%d", dbName, principalID),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected resource type: %s", rt.Id)
+	}
+}
+
+// ensureDatabaseUser creates a database user for the server principal if it does not have one yet.
+func ensureDatabaseUser(ctx context.Context, c *mssqldb.Client, dbName string, user *mssqldb.UserModel) error {
+	_, err := ensureDatabaseUserForLogin(ctx, c, dbName, user.ID, user.Name)
+	return err
+}
+
+// ensureDatabaseUserForLogin returns the name of the database user of a login, a user or a group, creating the user if it does not exist yet.
+func ensureDatabaseUserForLogin(ctx context.Context, c *mssqldb.Client, dbName string, loginID string, loginName string) (string, error) {
+	l := ctxzap.Extract(ctx)
+
+	dbUser, err := c.GetUserFromDb(ctx, dbName, loginID)
+	if err != nil {
+		return "", err
+	}
+
+	if dbUser != nil {
+		return dbUser.Name, nil
+	}
+
+	// A database restored from another server keeps the users of the logins there. When one of them has the name of
+	// the login, it is mapped to the login instead of creating a user, which would fail because the name is taken.
+	orphan, err := c.GetDatabaseUserByName(ctx, dbName, loginName)
+	if err != nil {
+		return "", err
+	}
+	if orphan != nil && orphan.IsOrphaned {
+		l.Info("orphaned user found in database, mapping it to the principal", zap.String("user", loginID), zap.String("db", dbName))
+		return orphan.Name, c.RemapDatabaseUser(ctx, dbName, orphan.Name, loginName)
+	}
+
+	l.Info("user not found in database, creating user for principal", zap.String("user", loginID))
+
+	return loginName, c.CreateDatabaseUserForPrincipal(ctx, dbName, loginName)
+}
+
 func newDatabaseSyncer(ctx context.Context, c *mssqldb.Client) *databaseSyncer {
 	return &databaseSyncer{
 		resourceType: resourceTypeDatabase,
@@ -21,6 +21,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// databaseRolePermissions maps the permissions that can be granted on a database role to their entitlement slugs.
+var databaseRolePermissions = map[string]string{
+	"AL": "alter",
+	"CL": "control",
+	"TO": "take-ownership",
+	"VW": "view-definition",
+}
+
+// databaseRolePermissionsPage is the pagination state for the permissions granted on the database role itself.
+const databaseRolePermissionsPage = "database-role-permissions"
+
 type databaseRolePrincipalSyncer struct {
 	resourceType *v2.ResourceType
 	client       *mssqldb.Client
@@ -55,13 +66,7 @@ func (d *databaseRolePrincipalSyncer) List(ctx context.Context, parentResourceID
 
 	var ret []*v2.Resource
 	for _, principalModel := range principals {
-		r, err := resource.NewRoleResource(
-			fmt.Sprintf("%s (%s)", principalModel.Name, db.Name),
-			d.ResourceType(ctx),
-			fmt.Sprintf("%s:%d", db.Name, principalModel.ID),
-			nil,
-			resource.WithParentResourceID(parentResourceID),
-		)
+		r, err := d.databaseRoleResource(ctx, db.Name, principalModel, parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -74,7 +79,30 @@ func (d *databaseRolePrincipalSyncer) List(ctx context.Context, parentResourceID
 func (d *databaseRolePrincipalSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var ret []*v2.Entitlement
 
-	ret = append(ret, enTypes.NewAssignmentEntitlement(resource, "member"))
+	ret = append(ret, enTypes.NewAssignmentEntitlement(
+		resource,
+		"member",
+		enTypes.WithGrantableTo(resourceTypeUser, resourceTypeGroup, resourceTypeDatabaseRole, resourceTypeDatabaseUser),
+	))
+	ret = append(ret, ownerEntitlement(resource))
+
+	for key, slug := range databaseRolePermissions {
+		name := mssqldb.DatabasePermissions[key]
+		ret = append(ret,
+			enTypes.NewPermissionEntitlement(resource, slug,
+				enTypes.WithDisplayName(name),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+			enTypes.NewPermissionEntitlement(resource, slug+permissionGrantOptionSuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (With Grant)", name)),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+			enTypes.NewPermissionEntitlement(resource, slug+permissionDenySuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (Deny)", name)),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+		)
+	}
 
 	return ret, "", nil, nil
 }
@@ -145,11 +173,83 @@ func (d *databaseRolePrincipalSyncer) Grants(
 	switch b.ResourceTypeID() {
 	case "init":
 		b.Pop()
+		b.Push(pagination.PageState{
+			ResourceTypeID: databaseRolePermissionsPage,
+			ResourceID:     resource.Id.Resource,
+		})
 		b.Push(pagination.PageState{
 			ResourceTypeID: resourceTypeDatabaseRole.Id,
 			ResourceID:     resource.Id.Resource,
 		})
 
+		dbName, roleID, err := parseDatabaseRoleResourceID(resource.Id.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		owner, err := d.client.GetDatabaseRoleOwner(ctx, dbName, roleID)
+		if err != nil && !errors.Is(err, mssqldb.ErrNoOwner) {
+			return nil, "", nil, err
+		}
+		if owner != nil {
+			ownerGrant, err := databaseOwnerGrant(ctx, d.client, dbName, resource, owner)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if ownerGrant != nil {
+				ret = append(ret, ownerGrant)
+			}
+		}
+
+	case databaseRolePermissionsPage:
+		dbName, roleID, err := parseDatabaseRoleResourceID(b.ResourceID())
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		principalPerms, nextPageToken, err := d.client.ListDatabasePrincipalPermissions(
+			ctx,
+			dbName,
+			roleID,
+			&mssqldb.Pager{Token: b.PageToken(), Size: pToken.Size},
+		)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		err = b.Next(nextPageToken)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		for _, p := range principalPerms {
+			rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
+			if err != nil {
+				l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+				continue
+			}
+
+			principalID, err := databasePrincipalResourceID(ctx, d.client, dbName, p.PrincipalID, p.PrincipalName, rt)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if principalID == nil {
+				continue
+			}
+
+			for _, perm := range strings.Split(p.Permissions, ",") {
+				permSlug, ok := databaseRolePermissions[strings.TrimSpace(perm)]
+				if !ok {
+					continue
+				}
+
+				slug, ok := permissionSlug(permSlug, p.State)
+				if !ok {
+					continue
+				}
+				ret = append(ret, grTypes.NewGrant(resource, slug, principalID))
+			}
+		}
+
 	case resourceTypeDatabaseRole.Id:
 		idParts := strings.Split(b.ResourceID(), ":")
 		if len(idParts) != 2 {
@@ -177,20 +277,22 @@ func (d *databaseRolePrincipalSyncer) Grants(
 			case "S", "E", "K", "C", "U", "X", "G":
 				serverPrincipal, err := d.client.GetServerPrincipalForDatabasePrincipal(ctx, idParts[0], dbPrincipal.ID)
 				if err != nil {
-					if errors.Is(err, mssqldb.ErrNoServerPrincipal) {
-						l.Debug("no server principal for database principal", zap.String("user", dbPrincipal.Name), zap.String("role_id", b.ResourceID()))
-						continue
+					if !errors.Is(err, mssqldb.ErrNoServerPrincipal) {
+						return nil, "", nil, err
 					}
-					return nil, "", nil, err
 				}
 
-				rt := resourceTypeUser
-
-				if dbPrincipal.Type == "G" || dbPrincipal.Type == "X" {
-					rt = resourceTypeGroup
+				switch {
+				case serverPrincipal != nil && !d.client.PrincipalAllowed(serverPrincipal.Name):
+					continue
+				case serverPrincipal == nil:
+					l.Debug("no server principal for database principal, using database user", zap.String("user", dbPrincipal.Name), zap.String("role_id", b.ResourceID()))
+					principalID, err = sdkResources.NewResourceID(resourceTypeDatabaseUser, fmt.Sprintf("%s:%d", idParts[0], dbPrincipal.ID))
+				case dbPrincipal.Type == "G" || dbPrincipal.Type == "X":
+					principalID, err = sdkResources.NewResourceID(resourceTypeGroup, serverPrincipal.ID)
+				default:
+					principalID, err = sdkResources.NewResourceID(resourceTypeUser, serverPrincipal.ID)
 				}
-
-				principalID, err = sdkResources.NewResourceID(rt, serverPrincipal.ID)
 				if err != nil {
 					return nil, "", nil, err
 				}
@@ -207,6 +309,11 @@ func (d *databaseRolePrincipalSyncer) Grants(
 				if err != nil {
 					return nil, "", nil, err
 				}
+			case "A":
+				principalID, err = sdkResources.NewResourceID(resourceTypeApplicationRole, fmt.Sprintf("%s:%d", idParts[0], dbPrincipal.ID))
+				if err != nil {
+					return nil, "", nil, err
+				}
 			default:
 				l.Error("unknown db principal type", zap.String("type", dbPrincipal.Type), zap.Any("db_principal", dbPrincipal), zap.String("role_id", b.ResourceID()))
 				continue
@@ -236,12 +343,6 @@ func (d *databaseRolePrincipalSyncer) Grants(
 func (d *databaseRolePrincipalSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
 	var err error
 
-	l := ctxzap.Extract(ctx)
-
-	if resource.Id.ResourceType != resourceTypeUser.Id {
-		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
-	}
-
 	// database-role:baton_test:6:member
 	splitId := strings.Split(entitlement.Id, ":")
 	if len(splitId) != 4 {
@@ -251,6 +352,13 @@ func (d *databaseRolePrincipalSyncer) Grant(ctx context.Context, resource *v2.Re
 	dbName := splitId[1]
 	roleId := splitId[2]
 
+	if splitId[3] != "member" {
+		if resource.Id.ResourceType != resourceTypeUser.Id {
+			return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+		}
+		return d.grantPermission(ctx, resource, entitlement, dbName, roleId, splitId[3])
+	}
+
 	var role *mssqldb.RoleModel
 
 	role, err = d.client.GetDatabaseRole(ctx, dbName, roleId)
@@ -258,68 +366,353 @@ func (d *databaseRolePrincipalSyncer) Grant(ctx context.Context, resource *v2.Re
 		return nil, nil, err
 	}
 
-	dbUser, err := d.client.GetUserFromDb(ctx, dbName, resource.Id.Resource)
+	if resource.Id.ResourceType == resourceTypeDatabaseRole.Id {
+		err = d.checkNestingCycle(ctx, dbName, role, resource.Id.Resource)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	member, err := d.memberName(ctx, dbName, resource.Id, true)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if dbUser == nil {
-		l.Info("user not found in database, creating user for principal", zap.String("user", resource.Id.Resource))
+	err = d.client.AddUserToDatabaseRole(ctx, role.Name, dbName, member)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grants := []*v2.Grant{
+		grTypes.NewGrant(entitlement.Resource, "member", resource.Id),
+	}
+
+	return grants, nil, nil
+}
+
+func (d *databaseRolePrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	// database-role:baton_test:6:member
+	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
 
-		user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	dbName := splitId[1]
+	roleId := splitId[2]
+
+	if splitId[3] != "member" {
+		user, err := d.client.GetUserPrincipal(ctx, grant.Principal.Id.Resource)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
+		return nil, d.revokePermission(ctx, user, dbName, roleId, splitId[3])
+	}
 
-		err = d.client.CreateDatabaseUserForPrincipal(ctx, dbName, user.Name)
+	role, err := d.client.GetDatabaseRole(ctx, dbName, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := d.memberName(ctx, dbName, grant.Principal.Id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.client.RevokeUserToDatabaseRole(ctx, role.Name, dbName, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, err
+}
+
+// memberName returns the name of the database principal to add to or drop from a database role.
+// Users and groups are resolved to their database user, which is created first when create is set.
+func (d *databaseRolePrincipalSyncer) memberName(ctx context.Context, dbName string, principal *v2.ResourceId, create bool) (string, error) {
+	var loginID, loginName string
+
+	switch principal.ResourceType {
+	case resourceTypeUser.Id:
+		user, err := d.client.GetUserPrincipal(ctx, principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		loginID, loginName = user.ID, user.Name
+
+	case resourceTypeGroup.Id:
+		group, err := d.client.GetGroupPrincipal(ctx, principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		loginID, loginName = group.ID, group.Name
+
+	case resourceTypeDatabaseRole.Id:
+		roleDB, roleID, err := parseDatabaseRoleResourceID(principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		if roleDB != dbName {
+			return "", fmt.Errorf("database role %s is not in database %s", principal.Resource, dbName)
+		}
+		role, err := d.client.GetDatabaseRole(ctx, dbName, roleID)
+		if err != nil {
+			return "", err
+		}
+		return role.Name, nil
+
+	case resourceTypeDatabaseUser.Id:
+		userDB, userID, err := parseDatabaseUserResourceID(principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		if userDB != dbName {
+			return "", fmt.Errorf("database user %s is not in database %s", principal.Resource, dbName)
+		}
+		user, err := d.client.GetDatabaseUserPrincipal(ctx, dbName, userID)
+		if err != nil {
+			return "", err
+		}
+		return user.Name, nil
+
+	default:
+		return "", fmt.Errorf("resource type %s is not supported as a database role member", principal.ResourceType)
+	}
+
+	if create {
+		return ensureDatabaseUserForLogin(ctx, d.client, dbName, loginID, loginName)
+	}
+
+	dbUser, err := d.client.GetUserFromDb(ctx, dbName, loginID)
+	if err != nil {
+		return "", err
+	}
+	if dbUser == nil {
+		return "", fmt.Errorf("principal %s has no user in database %s", loginName, dbName)
+	}
+
+	return dbUser.Name, nil
+}
+
+// checkNestingCycle returns an error if adding the member role to the role would make the roles members of each other.
+func (d *databaseRolePrincipalSyncer) checkNestingCycle(ctx context.Context, dbName string, role *mssqldb.RoleModel, memberResourceID string) error {
+	_, memberRoleID, err := parseDatabaseRoleResourceID(memberResourceID)
+	if err != nil {
+		return err
+	}
+
+	if strconv.FormatInt(role.ID, 10) == memberRoleID {
+		return fmt.Errorf("cannot add database role %s to itself", role.Name)
+	}
+
+	isMember, err := d.client.IsDatabaseRoleMember(ctx, dbName, memberRoleID, strconv.FormatInt(role.ID, 10))
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return fmt.Errorf("cannot add database role %s to %s: %s is already a member of it", memberResourceID, role.Name, role.Name)
+	}
+
+	return nil
+}
+
+// grantPermission grants a permission on the database role, e.g. ALTER ON ROLE::[app_readers].
+func (d *databaseRolePrincipalSyncer) grantPermission(
+	ctx context.Context,
+	resource *v2.Resource,
+	entitlement *v2.Entitlement,
+	dbName string,
+	roleID string,
+	slug string,
+) ([]*v2.Grant, annotations.Annotations, error) {
+	var permission, state string
+	var err error
+	if slug != ownerSlug {
+		permission, state, err = parseDatabaseRolePermissionSlug(slug)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
-	err = d.client.AddUserToDatabaseRole(ctx, role.Name, dbName, resource.Id.Resource)
+	role, err := d.client.GetDatabaseRole(ctx, dbName, roleID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	grants := []*v2.Grant{
-		grTypes.NewGrant(resource, "member", &v2.ResourceId{
-			Resource:     resource.Id.Resource,
-			ResourceType: resourceTypeUser.Id,
-		}),
+	user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return grants, nil, nil
+	err = ensureDatabaseUser(ctx, d.client, dbName, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case slug == ownerSlug:
+		err = d.client.ChangeDatabaseRoleOwner(ctx, dbName, role.Name, user.Name)
+	case state == permissionStateDeny:
+		err = d.client.DenyPermissionOnDatabasePrincipal(ctx, permission, dbName, mssqldb.DatabasePrincipalClassRole, role.Name, user.Name)
+	default:
+		err = d.client.GrantPermissionOnDatabasePrincipal(
+			ctx, permission, dbName, mssqldb.DatabasePrincipalClassRole, role.Name, user.Name, state == permissionStateGrantWithGrant,
+		)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grTypes.NewGrant(entitlement.Resource, slug, &v2.ResourceId{
+		Resource:     user.ID,
+		ResourceType: resourceTypeUser.Id,
+	})
+
+	return []*v2.Grant{newGrant}, nil, nil
 }
 
-func (d *databaseRolePrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
-	userId := grant.Principal.Id.Resource
+// revokePermission revokes a granted or denied permission on the database role.
+func (d *databaseRolePrincipalSyncer) revokePermission(ctx context.Context, user *mssqldb.UserModel, dbName string, roleID string, slug string) error {
+	if slug == ownerSlug {
+		return errRevokeOwner
+	}
+
+	permission, state, err := parseDatabaseRolePermissionSlug(slug)
+	if err != nil {
+		return err
+	}
+
+	role, err := d.client.GetDatabaseRole(ctx, dbName, roleID)
+	if err != nil {
+		return err
+	}
+
+	return d.client.RevokePermissionOnDatabasePrincipal(
+		ctx, permission, dbName, mssqldb.DatabasePrincipalClassRole, role.Name, user.Name, state == permissionStateGrantWithGrant,
+	)
+}
+
+// parseDatabaseRolePermissionSlug returns the permission code and state for a database role permission slug, e.g. alter-grant.
+func parseDatabaseRolePermissionSlug(slug string) (string, string, error) {
+	permSlug, state := parsePermissionSlug(slug)
+	for key, value := range databaseRolePermissions {
+		if value == permSlug {
+			return key, state, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("unexpected database role entitlement: %s", slug)
+}
+
+// parseDatabaseRoleResourceID splits a database role resource ID into the database name and role ID.
+func parseDatabaseRoleResourceID(id string) (string, string, error) {
+	idParts := strings.Split(id, ":")
+	if len(idParts) != 2 {
+		return "", "", fmt.Errorf("invalid database role id: %s", id)
+	}
+
+	return idParts[0], idParts[1], nil
+}
+
+func (d *databaseRolePrincipalSyncer) databaseRoleResource(
+	ctx context.Context,
+	dbName string,
+	role *mssqldb.RoleModel,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	return resource.NewRoleResource(
+		fmt.Sprintf("%s (%s)", role.Name, dbName),
+		d.ResourceType(ctx),
+		fmt.Sprintf("%s:%d", dbName, role.ID),
+		nil,
+		resource.WithParentResourceID(parentResourceID),
+	)
+}
+
+// Create creates a user-defined role in the parent database. The owner can be set with the 'owner' field of the role profile.
+func (d *databaseRolePrincipalSyncer) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	parentResourceID := resource.GetParentResourceId()
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeDatabase.Id {
+		return nil, nil, fmt.Errorf("database roles must have a database as the parent resource")
+	}
+
+	name := resource.GetDisplayName()
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing database role name")
+	}
+
+	owner, err := roleOwnerFromResource(resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbID, err := strconv.ParseInt(parentResourceID.Resource, 10, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := d.client.GetDatabase(ctx, dbID)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	user, err := d.client.GetUserPrincipal(ctx, userId)
+	err = d.client.CreateDatabaseRole(ctx, db.Name, name, owner)
+	if err != nil {
+		l.Error("failed to create database role", zap.Error(err), zap.String("name", name), zap.String("database", db.Name))
+		return nil, nil, fmt.Errorf("failed to create database role: %w", err)
+	}
+
+	role, err := d.client.GetDatabaseRoleByName(ctx, db.Name, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get database role: %w", err)
+	}
+
+	r, err := d.databaseRoleResource(ctx, db.Name, role, parentResourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, nil, nil
+}
+
+// Delete drops a user-defined database role. Fixed roles, roles that still have members and roles that own schemas are not dropped.
+func (d *databaseRolePrincipalSyncer) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	dbName, roleID, err := parseDatabaseRoleResourceID(resourceId.Resource)
 	if err != nil {
 		return nil, err
 	}
 
-	// database-role:baton_test:6:member
-	splitId := strings.Split(grant.Entitlement.Id, ":")
-	if len(splitId) != 4 {
-		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	role, err := d.client.GetDatabaseRole(ctx, dbName, roleID)
+	if err != nil {
+		return nil, err
 	}
 
-	dbName := splitId[1]
-	roleId := splitId[2]
+	if role.IsFixedRole || role.Name == mssqldb.PublicRoleName {
+		return nil, fmt.Errorf("cannot delete fixed database role %s", role.Name)
+	}
 
-	role, err := d.client.GetDatabaseRole(ctx, dbName, roleId)
+	members, err := d.client.CountDatabaseRoleMembers(ctx, dbName, roleID)
 	if err != nil {
 		return nil, err
 	}
+	if members > 0 {
+		return nil, fmt.Errorf("cannot delete database role %s: the role has %d members", role.Name, members)
+	}
 
-	err = d.client.RevokeUserToDatabaseRole(ctx, role.Name, dbName, user.Name)
+	schemas, err := d.client.CountSchemasOwnedBy(ctx, dbName, roleID)
 	if err != nil {
 		return nil, err
 	}
+	if schemas > 0 {
+		return nil, fmt.Errorf("cannot delete database role %s: the role owns %d schemas", role.Name, schemas)
+	}
 
-	return nil, err
+	err = d.client.DropDatabaseRole(ctx, dbName, role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
 }
 
 func newDatabaseRolePrincipalSyncer(ctx context.Context, c *mssqldb.Client) *databaseRolePrincipalSyncer {
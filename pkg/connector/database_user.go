@@ -0,0 +1,390 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// databaseUserSyncer syncs the users of a database. Users that are not mapped to a server login, e.g. contained users,
+// users created WITHOUT LOGIN and Entra users created FROM EXTERNAL PROVIDER, are the grantees of their permissions and
+// role memberships. Users with a login only carry the permissions granted on the user itself, such as IMPERSONATE,
+// and are otherwise represented by the login's user or group resource.
+type databaseUserSyncer struct {
+	resourceType *v2.ResourceType
+	client       *mssqldb.Client
+}
+
+// databaseUserPermissions are the permissions synced on database users.
+var databaseUserPermissions = map[string]string{
+	"IM": "Impersonate",
+}
+
+func (d *databaseUserSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
+	return d.resourceType
+}
+
+func (d *databaseUserSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	if parentResourceID.ResourceType != resourceTypeDatabase.Id {
+		return nil, "", nil, fmt.Errorf("database users must have a database as the parent resource")
+	}
+
+	dbID, err := strconv.ParseInt(parentResourceID.Resource, 10, 64)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	db, err := d.client.GetDatabase(ctx, dbID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	users, nextPageToken, err := d.client.ListDatabaseUsers(ctx, db.Name, &mssqldb.Pager{Token: pToken.Token, Size: pToken.Size})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var ret []*v2.Resource
+	for _, userModel := range users {
+		if userModel.LoginName != "" && !d.client.PrincipalAllowed(userModel.LoginName) {
+			continue
+		}
+
+		profile := map[string]interface{}{
+			"database":            db.Name,
+			"type":                userModel.TypeDesc,
+			"authentication_type": userModel.AuthenticationType,
+		}
+		if userModel.DefaultSchema != "" {
+			profile["default_schema"] = userModel.DefaultSchema
+		}
+		if userModel.LoginName != "" {
+			profile["login"] = userModel.LoginName
+		}
+
+		status := resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED)
+		if userModel.IsOrphaned {
+			// Orphaned users cannot connect until they are mapped to a login, see the remap_orphaned_user action.
+			status = resource.WithDetailedStatus(v2.UserTrait_Status_STATUS_DISABLED, "orphaned")
+			profile["orphaned"] = true
+		}
+
+		userOpts := []resource.UserTraitOption{
+			status,
+			resource.WithUserLogin(userModel.Name),
+			resource.WithUserProfile(profile),
+		}
+
+		if _, err = mail.ParseAddress(userModel.Name); err == nil {
+			userOpts = append(userOpts, resource.WithEmail(userModel.Name, true))
+		}
+
+		r, err := resource.NewUserResource(
+			fmt.Sprintf("%s (%s)", userModel.Name, db.Name),
+			d.ResourceType(ctx),
+			fmt.Sprintf("%s:%d", db.Name, userModel.ID),
+			userOpts,
+			resource.WithParentResourceID(parentResourceID),
+		)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *databaseUserSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var ret []*v2.Entitlement
+
+	for key, name := range databaseUserPermissions {
+		ret = append(ret,
+			enTypes.NewPermissionEntitlement(resource, key,
+				enTypes.WithDisplayName(name),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+			enTypes.NewPermissionEntitlement(resource, key+permissionGrantOptionSuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (With Grant)", name)),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+			enTypes.NewPermissionEntitlement(resource, key+permissionDenySuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (Deny)", name)),
+				enTypes.WithGrantableTo(resourceTypeUser),
+			),
+		)
+	}
+
+	return ret, "", nil, nil
+}
+
+func (d *databaseUserSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	dbName, principalID, err := parseDatabaseUserResourceID(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	principalPerms, nextPageToken, err := d.client.ListDatabasePrincipalPermissions(
+		ctx,
+		dbName,
+		principalID,
+		&mssqldb.Pager{Token: pToken.Token, Size: pToken.Size},
+	)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var ret []*v2.Grant
+	for _, p := range principalPerms {
+		rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
+		if err != nil {
+			l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+			continue
+		}
+
+		granteeID, err := databasePrincipalResourceID(ctx, d.client, dbName, p.PrincipalID, p.PrincipalName, rt)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if granteeID == nil {
+			continue
+		}
+
+		for _, perm := range strings.Split(p.Permissions, ",") {
+			perm = strings.TrimSpace(perm)
+			if _, ok := databaseUserPermissions[perm]; !ok {
+				continue
+			}
+
+			slug, ok := permissionSlug(perm, p.State)
+			if !ok {
+				continue
+			}
+			ret = append(ret, grTypes.NewGrant(resource, slug, granteeID))
+		}
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *databaseUserSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if resource.Id.ResourceType != resourceTypeUser.Id {
+		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+	}
+
+	// database-user:baton_test:5:IM-grant
+	splitId := strings.Split(entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	permission, state := parsePermissionSlug(splitId[3])
+	if _, ok := databaseUserPermissions[permission]; !ok {
+		return nil, nil, fmt.Errorf("permission %s is not supported", permission)
+	}
+
+	target, err := d.client.GetDatabaseUserPrincipal(ctx, dbName, splitId[2])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ensureDatabaseUser(ctx, d.client, dbName, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state == permissionStateDeny {
+		err = d.client.DenyPermissionOnDatabasePrincipal(ctx, permission, dbName, mssqldb.DatabasePrincipalClassUser, target.Name, user.Name)
+	} else {
+		err = d.client.GrantPermissionOnDatabasePrincipal(
+			ctx, permission, dbName, mssqldb.DatabasePrincipalClassUser, target.Name, user.Name, state == permissionStateGrantWithGrant,
+		)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grTypes.NewGrant(entitlement.Resource, splitId[3], &v2.ResourceId{
+		Resource:     user.ID,
+		ResourceType: resourceTypeUser.Id,
+	})
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+func (d *databaseUserSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if grant.Principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("resource type %s is not supported for revoking", grant.Principal.Id.ResourceType)
+	}
+
+	// database-user:baton_test:5:IM-grant
+	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	permission, state := parsePermissionSlug(splitId[3])
+	if _, ok := databaseUserPermissions[permission]; !ok {
+		return nil, fmt.Errorf("permission %s is not supported", permission)
+	}
+
+	target, err := d.client.GetDatabaseUserPrincipal(ctx, dbName, splitId[2])
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, grant.Principal.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.client.RevokePermissionOnDatabasePrincipal(
+		ctx, permission, dbName, mssqldb.DatabasePrincipalClassUser, target.Name, user.Name, state == permissionStateGrantWithGrant,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("revoked permission", zap.String("permission", permission), zap.String("user", user.Name), zap.String("target", target.Name))
+	return nil, nil
+}
+
+// parseDatabaseUserResourceID splits a database user resource ID into the database name and principal ID.
+func parseDatabaseUserResourceID(id string) (string, string, error) {
+	idParts := strings.Split(id, ":")
+	if len(idParts) != 2 {
+		return "", "", fmt.Errorf("invalid database user id: %s", id)
+	}
+
+	return idParts[0], idParts[1], nil
+}
+
+func newDatabaseUserSyncer(ctx context.Context, c *mssqldb.Client) *databaseUserSyncer {
+	return &databaseUserSyncer{
+		resourceType: resourceTypeDatabaseUser,
+		client:       c,
+	}
+}
+
+const (
+	remapOrphanedUserActionName = "remap_orphaned_user"
+	dropOrphanedUserActionName  = "drop_orphaned_user"
+)
+
+// registerOrphanedUserActions registers the actions that repair the orphaned users of a database,
+// either by mapping them to a login with ALTER USER ... WITH LOGIN or by dropping them.
+func registerOrphanedUserActions(m *actionManager, c *mssqldb.Client) {
+	databaseArg := stringActionField("database", "Database", "The name of the database of the orphaned user.", true)
+	userArg := stringActionField("user", "User", "The name of the orphaned user.", true)
+
+	m.register(&v2.BatonActionSchema{
+		Name:        remapOrphanedUserActionName,
+		DisplayName: "Remap Orphaned User",
+		Description: "Maps an orphaned database user to a login, keeping its permissions and role memberships.",
+		Arguments: []*config.Field{
+			databaseArg,
+			userArg,
+			stringActionField("login", "Login", "The name of the login to map the user to. Defaults to the name of the user.", false),
+		},
+		ReturnTypes: actionSuccessReturnTypes,
+	}, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+		dbName, user, err := orphanedUserArgs(ctx, c, args)
+		if err != nil {
+			return nil, err
+		}
+
+		login, err := actionStringArg(args, "login", false)
+		if err != nil {
+			return nil, err
+		}
+		if login == "" {
+			login = user.Name
+		}
+
+		if _, err = c.GetUserPrincipalByName(ctx, login); err != nil {
+			return nil, err
+		}
+
+		err = c.RemapDatabaseUser(ctx, dbName, user.Name, login)
+		if err != nil {
+			return nil, err
+		}
+
+		return actionSuccess(), nil
+	})
+
+	m.register(&v2.BatonActionSchema{
+		Name:        dropOrphanedUserActionName,
+		DisplayName: "Drop Orphaned User",
+		Description: "Drops an orphaned database user.",
+		Arguments:   []*config.Field{databaseArg, userArg},
+		ReturnTypes: actionSuccessReturnTypes,
+	}, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+		dbName, user, err := orphanedUserArgs(ctx, c, args)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.DropDatabaseUser(ctx, dbName, user.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		return actionSuccess(), nil
+	})
+}
+
+// orphanedUserArgs returns the database and the user an orphaned user action was invoked with,
+// and an error if the user does not exist or is not orphaned.
+func orphanedUserArgs(ctx context.Context, c *mssqldb.Client, args *structpb.Struct) (string, *mssqldb.DatabaseUserModel, error) {
+	dbName, err := actionStringArg(args, "database", true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	userName, err := actionStringArg(args, "user", true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	user, err := c.GetDatabaseUserByName(ctx, dbName, userName)
+	if err != nil {
+		return "", nil, err
+	}
+	if user == nil {
+		return "", nil, fmt.Errorf("user %s not found in database %s", userName, dbName)
+	}
+	if !user.IsOrphaned {
+		return "", nil, fmt.Errorf("user %s in database %s is not orphaned", userName, dbName)
+	}
+
+	return dbName, user, nil
+}
@@ -49,11 +49,34 @@ func (d *groupPrincipalSyncer) List(ctx context.Context, parentResourceID *v2.Re
 }
 
 func (d *groupPrincipalSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	return nil, "", nil, nil
+	return serverPrincipalPermissionEntitlements(d.client, resource, loginPermissions), "", nil, nil
 }
 
 func (d *groupPrincipalSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	return nil, "", nil, nil
+	ret, nextPageToken, err := serverPrincipalPermissionGrants(ctx, d.client, resource, loginPermissions, &mssqldb.Pager{Token: pToken.Token, Size: pToken.Size})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *groupPrincipalSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	grants, err := grantServerPrincipalPermission(ctx, d.client, resource, entitlement, loginPermissions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return grants, nil, nil
+}
+
+func (d *groupPrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	err := revokeServerPrincipalPermission(ctx, d.client, grant, loginPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
 }
 
 func newGroupPrincipalSyncer(ctx context.Context, c *mssqldb.Client) *groupPrincipalSyncer {
@@ -2,6 +2,7 @@ package connector
 
 import (
 	"fmt"
+	"strings"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 )
@@ -23,6 +24,8 @@ func resourceTypeFromDatabasePrincipal(pType string) (*v2.ResourceType, error) {
 	switch pType {
 	case "R":
 		return resourceTypeDatabaseRole, nil
+	case "A":
+		return resourceTypeApplicationRole, nil
 	case "G", "X":
 		return resourceTypeGroup, nil
 	case "S", "U", "C", "E", "K":
@@ -31,3 +34,38 @@ func resourceTypeFromDatabasePrincipal(pType string) (*v2.ResourceType, error) {
 		return nil, fmt.Errorf("unknown principal type: %s", pType)
 	}
 }
+
+// Permission states as found in sys.server_permissions and sys.database_permissions.
+const (
+	permissionStateGrant          = "G"
+	permissionStateGrantWithGrant = "W"
+	permissionStateDeny           = "D"
+	permissionGrantOptionSuffix   = "-grant"
+	permissionDenySuffix          = "-deny"
+)
+
+// permissionSlug returns the entitlement slug for a permission in the given state:
+// the permission code for a grant, "<code>-grant" for a grant with grant option and "<code>-deny" for a deny.
+func permissionSlug(permission string, state string) (string, bool) {
+	switch state {
+	case permissionStateGrant:
+		return permission, true
+	case permissionStateGrantWithGrant:
+		return permission + permissionGrantOptionSuffix, true
+	case permissionStateDeny:
+		return permission + permissionDenySuffix, true
+	default:
+		return "", false
+	}
+}
+
+// parsePermissionSlug is the inverse of permissionSlug and returns the permission code and its state.
+func parsePermissionSlug(slug string) (string, string) {
+	if permission, ok := strings.CutSuffix(slug, permissionGrantOptionSuffix); ok {
+		return permission, permissionStateGrantWithGrant
+	}
+	if permission, ok := strings.CutSuffix(slug, permissionDenySuffix); ok {
+		return permission, permissionStateDeny
+	}
+	return slug, permissionStateGrant
+}
@@ -0,0 +1,69 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// lastLogins holds the last successful login of each login, read once per sync from the configured source.
+type lastLogins struct {
+	source   mssqldb.LastLoginSource
+	location string
+
+	mu     sync.Mutex
+	logins map[string]time.Time
+}
+
+func newLastLogins(source string, location string) (*lastLogins, error) {
+	s := mssqldb.LastLoginSource(source)
+	if s == "" {
+		s = mssqldb.LastLoginSourceNone
+	}
+
+	err := s.Validate(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lastLogins{
+		source:   s,
+		location: location,
+	}, nil
+}
+
+// refresh reads the last logins from the source. A source that cannot be read, e.g. because the connector
+// lacks permission, is logged and leaves the last logins unset rather than failing the sync.
+func (ll *lastLogins) refresh(ctx context.Context, c *mssqldb.Client) {
+	if ll == nil || ll.source == mssqldb.LastLoginSourceNone {
+		return
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	logins, err := c.ListLastLogins(ctx, ll.source, ll.location)
+	if err != nil {
+		l.Warn("unable to read last logins", zap.String("source", string(ll.source)), zap.Error(err))
+		logins = nil
+	}
+
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+	ll.logins = logins
+}
+
+// get returns the last login of the login with the given name.
+func (ll *lastLogins) get(name string) (time.Time, bool) {
+	if ll == nil {
+		return time.Time{}, false
+	}
+
+	ll.mu.Lock()
+	defer ll.mu.Unlock()
+	t, ok := ll.logins[name]
+	return t, ok
+}
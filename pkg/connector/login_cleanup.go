@@ -0,0 +1,291 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// keepDatabaseUsers leaves the database users of a deleted login in place; they show up as orphaned users.
+	keepDatabaseUsers = "keep"
+	// dropDatabaseUsers drops the database users of a deleted login after transferring what they own to the fallback owner, or to dbo.
+	dropDatabaseUsers = "drop"
+	// remapDatabaseUsers maps the database users of a deleted login to the fallback owner.
+	// A user is dropped instead in the databases where the fallback owner already has a user.
+	remapDatabaseUsers = "remap"
+)
+
+// loginDeleteOptions controls the cleanup done before a login is dropped.
+type loginDeleteOptions struct {
+	killSessions  bool
+	databaseUsers string
+	fallbackOwner string
+}
+
+func newLoginDeleteOptions(killSessions bool, databaseUsers string, fallbackOwner string) (*loginDeleteOptions, error) {
+	switch databaseUsers {
+	case "":
+		databaseUsers = keepDatabaseUsers
+	case keepDatabaseUsers, dropDatabaseUsers:
+	case remapDatabaseUsers:
+		if fallbackOwner == "" {
+			return nil, fmt.Errorf("a fallback owner is required to remap database users")
+		}
+	default:
+		return nil, fmt.Errorf("invalid delete-database-users value %q: must be keep, drop or remap", databaseUsers)
+	}
+
+	return &loginDeleteOptions{
+		killSessions:  killSessions,
+		databaseUsers: databaseUsers,
+		fallbackOwner: fallbackOwner,
+	}, nil
+}
+
+// loginCleanupReport records what was changed while cleaning up a login.
+type loginCleanupReport struct {
+	killedSessions        []int64
+	droppedDatabaseUsers  []string
+	remappedDatabaseUsers []string
+	transferredOwnership  []string
+}
+
+// annotation returns the report as a struct annotation.
+func (r *loginCleanupReport) annotation() (*structpb.Struct, error) {
+	killedSessions := make([]interface{}, 0, len(r.killedSessions))
+	for _, id := range r.killedSessions {
+		killedSessions = append(killedSessions, id)
+	}
+
+	return structpb.NewStruct(map[string]interface{}{
+		"killed_sessions":         killedSessions,
+		"dropped_database_users":  stringValues(r.droppedDatabaseUsers),
+		"remapped_database_users": stringValues(r.remappedDatabaseUsers),
+		"transferred_ownership":   stringValues(r.transferredOwnership),
+	})
+}
+
+func stringValues(values []string) []interface{} {
+	ret := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// fallbackOwner is the login that takes over what a deleted login owns.
+type fallbackOwner struct {
+	login *mssqldb.UserModel
+	// databases are the databases owned by the fallback owner, where it is the dbo user.
+	databases map[string]bool
+}
+
+// hasDatabaseUser reports whether the fallback owner is mapped to a user in the database.
+func (f *fallbackOwner) hasDatabaseUser(ctx context.Context, c *mssqldb.Client, db string) (bool, error) {
+	if f.databases[db] {
+		return true, nil
+	}
+
+	dbUser, err := c.GetUserFromDb(ctx, db, f.login.ID)
+	if err != nil {
+		return false, err
+	}
+	return dbUser != nil, nil
+}
+
+// databaseUser returns the name of the fallback owner's user in the database, creating the user if needed.
+func (f *fallbackOwner) databaseUser(ctx context.Context, c *mssqldb.Client, db string) (string, error) {
+	if f.databases[db] {
+		return "dbo", nil
+	}
+	return ensureDatabaseUserForLogin(ctx, c, db, f.login.ID, f.login.Name)
+}
+
+// cleanupLogin prepares a login to be dropped according to the delete options.
+// The fallback owner is looked up first, so a missing fallback owner fails the delete before anything is changed.
+// The login is disabled before its sessions are killed so they cannot be re-opened.
+func cleanupLogin(ctx context.Context, c *mssqldb.Client, user *mssqldb.UserModel, opts *loginDeleteOptions) (*loginCleanupReport, error) {
+	l := ctxzap.Extract(ctx)
+	report := &loginCleanupReport{}
+
+	if opts == nil {
+		return report, nil
+	}
+
+	var fallback *fallbackOwner
+	if opts.fallbackOwner != "" {
+		if opts.fallbackOwner == user.Name {
+			return nil, fmt.Errorf("cannot delete login %s: it is the fallback owner", user.Name)
+		}
+
+		login, err := c.GetUserPrincipalByName(ctx, opts.fallbackOwner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fallback owner: %w", err)
+		}
+		fallback = &fallbackOwner{login: login}
+	}
+
+	if opts.killSessions {
+		err := c.SetLoginDisabled(ctx, user.Name, true)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions, err := c.ListLoginSessions(ctx, user.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, sessionID := range sessions {
+			err = c.KillSession(ctx, sessionID)
+			if err != nil {
+				return nil, err
+			}
+			report.killedSessions = append(report.killedSessions, sessionID)
+		}
+	}
+
+	if fallback != nil {
+		databases, err := c.ListDatabasesOwnedByLogin(ctx, user.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, db := range databases {
+			err = c.ChangeDatabaseOwner(ctx, db, fallback.login.Name)
+			if err != nil {
+				return nil, err
+			}
+			report.transferredOwnership = append(report.transferredOwnership, fmt.Sprintf("DATABASE::%s", db))
+		}
+
+		jobs, err := c.ListJobsOwnedByLogin(ctx, user.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			err = c.ChangeJobOwner(ctx, job.ID, fallback.login.Name)
+			if err != nil {
+				return nil, err
+			}
+			report.transferredOwnership = append(report.transferredOwnership, fmt.Sprintf("JOB::%s", job.Name))
+		}
+
+		// Listed after the transfer, so it includes the databases the fallback owner just took over.
+		fallbackDatabases, err := c.ListDatabasesOwnedByLogin(ctx, fallback.login.Name)
+		if err != nil {
+			return nil, err
+		}
+		fallback.databases = make(map[string]bool, len(fallbackDatabases))
+		for _, db := range fallbackDatabases {
+			fallback.databases[db] = true
+		}
+	}
+
+	if opts.databaseUsers != keepDatabaseUsers || fallback != nil {
+		err := cleanupDatabaseUsers(ctx, c, user, opts, fallback, report)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l.Debug("cleaned up login",
+		zap.String("login", user.Name),
+		zap.Int64s("killed_sessions", report.killedSessions),
+		zap.Strings("dropped_database_users", report.droppedDatabaseUsers),
+		zap.Strings("remapped_database_users", report.remappedDatabaseUsers),
+		zap.Strings("transferred_ownership", report.transferredOwnership),
+	)
+
+	return report, nil
+}
+
+// cleanupDatabaseUsers drops or re-maps the users of the login in every online database.
+// The schemas and roles of the users are transferred to the fallback owner, also when the users are kept.
+func cleanupDatabaseUsers(
+	ctx context.Context,
+	c *mssqldb.Client,
+	user *mssqldb.UserModel,
+	opts *loginDeleteOptions,
+	fallback *fallbackOwner,
+	report *loginCleanupReport,
+) error {
+	l := ctxzap.Extract(ctx)
+
+	databases, err := c.ListOnlineDatabaseNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, db := range databases {
+		dbUser, err := c.GetUserFromDb(ctx, db, user.ID)
+		if err != nil {
+			return err
+		}
+		if dbUser == nil {
+			continue
+		}
+
+		qualifiedName := fmt.Sprintf("%s.%s", db, dbUser.Name)
+
+		action := opts.databaseUsers
+		if action == remapDatabaseUsers {
+			// A login is mapped to at most one user per database, so the user cannot be remapped where the fallback owner has one.
+			taken, err := fallback.hasDatabaseUser(ctx, c, db)
+			if err != nil {
+				return err
+			}
+			if !taken {
+				err = c.RemapDatabaseUser(ctx, db, dbUser.Name, fallback.login.Name)
+				if err != nil {
+					return err
+				}
+				report.remappedDatabaseUsers = append(report.remappedDatabaseUsers, qualifiedName)
+				continue
+			}
+
+			l.Info("fallback owner already has a user in the database, dropping the user instead of remapping it",
+				zap.String("user", dbUser.Name),
+				zap.String("db", db),
+			)
+			action = dropDatabaseUsers
+		}
+
+		// A user that owns schemas or roles cannot be dropped.
+		securables, err := c.ListSecurablesOwnedByDatabasePrincipal(ctx, db, dbUser.ID)
+		if err != nil {
+			return err
+		}
+		if len(securables) > 0 && (fallback != nil || action == dropDatabaseUsers) {
+			newOwner := "dbo"
+			if fallback != nil {
+				newOwner, err = fallback.databaseUser(ctx, c, db)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, securable := range securables {
+				err = c.ChangeSecurableOwner(ctx, db, securable, newOwner)
+				if err != nil {
+					return err
+				}
+				report.transferredOwnership = append(report.transferredOwnership, fmt.Sprintf("%s::%s.%s", securable.Class, db, securable.Name))
+			}
+		}
+
+		if action != dropDatabaseUsers {
+			continue
+		}
+
+		err = c.DropDatabaseUser(ctx, db, dbUser.Name)
+		if err != nil {
+			return err
+		}
+		report.droppedDatabaseUsers = append(report.droppedDatabaseUsers, qualifiedName)
+	}
+
+	return nil
+}
@@ -0,0 +1,632 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// serverIDSeparator separates the server name from the resource ID in multi-server mode, e.g. 'prod/261'.
+const serverIDSeparator = "/"
+
+// serverArgName is the argument naming the server an action runs on, and the account creation field naming the
+// server a login is created on, in multi-server mode.
+const serverArgName = "server"
+
+// ServerConnection is a named SQL Server instance synced in multi-server mode.
+type ServerConnection struct {
+	Name string
+	DSN  string
+}
+
+// ParseServerConnections parses server connections given as 'name=dsn'.
+// Names cannot contain the '/' used to qualify resource IDs with the server name, or ':'.
+func ParseServerConnections(values []string) ([]ServerConnection, error) {
+	seen := make(map[string]bool, len(values))
+
+	ret := make([]ServerConnection, 0, len(values))
+	for i, v := range values {
+		// The DSN holds credentials, so it is left out of the errors.
+		name, dsn, ok := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.TrimSpace(dsn) == "" {
+			return nil, fmt.Errorf("invalid server connection %d: expected name=dsn", i+1)
+		}
+		if strings.ContainsAny(name, serverIDSeparator+":") {
+			return nil, fmt.Errorf("invalid server name %s: it cannot contain '/' or ':'", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate server name %s", name)
+		}
+		seen[name] = true
+
+		ret = append(ret, ServerConnection{Name: name, DSN: dsn})
+	}
+
+	return ret, nil
+}
+
+func qualifyID(server string, id string) string {
+	return server + serverIDSeparator + id
+}
+
+// serverFromID returns the name of the server a qualified resource ID belongs to.
+func serverFromID(id string) (string, error) {
+	server, _, ok := strings.Cut(id, serverIDSeparator)
+	if !ok || server == "" {
+		return "", fmt.Errorf("resource id %s is not qualified with a server", id)
+	}
+	return server, nil
+}
+
+func unqualifyID(server string, id string) (string, error) {
+	ret, ok := strings.CutPrefix(id, server+serverIDSeparator)
+	if !ok {
+		return "", fmt.Errorf("resource id %s does not belong to server %s", id, server)
+	}
+	return ret, nil
+}
+
+// qualifyResourceID returns a copy of the resource ID qualified with the server.
+// IDs without a resource, e.g. of a resource being created, are left as they are.
+func qualifyResourceID(server string, id *v2.ResourceId) *v2.ResourceId {
+	if id == nil {
+		return nil
+	}
+
+	ret := proto.Clone(id).(*v2.ResourceId)
+	if ret.Resource != "" {
+		ret.Resource = qualifyID(server, ret.Resource)
+	}
+	return ret
+}
+
+func unqualifyResourceID(server string, id *v2.ResourceId) (*v2.ResourceId, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	ret := proto.Clone(id).(*v2.ResourceId)
+	if ret.Resource != "" {
+		var err error
+		ret.Resource, err = unqualifyID(server, ret.Resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// qualifyResource returns a copy of the resource with its ID and parent ID qualified with the server.
+func qualifyResource(server string, r *v2.Resource) *v2.Resource {
+	if r == nil {
+		return nil
+	}
+
+	ret := proto.Clone(r).(*v2.Resource)
+	ret.Id = qualifyResourceID(server, r.Id)
+	ret.ParentResourceId = qualifyResourceID(server, r.ParentResourceId)
+	return ret
+}
+
+func unqualifyResource(server string, r *v2.Resource) (*v2.Resource, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	var err error
+	ret := proto.Clone(r).(*v2.Resource)
+	ret.Id, err = unqualifyResourceID(server, r.Id)
+	if err != nil {
+		return nil, err
+	}
+	ret.ParentResourceId, err = unqualifyResourceID(server, r.ParentResourceId)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// rewriteEntitlementID replaces the resource ID in an entitlement ID, which is formatted as 'type:resource:slug'.
+func rewriteEntitlementID(id string, from *v2.ResourceId, to *v2.ResourceId) (string, error) {
+	slug, ok := strings.CutPrefix(id, fmt.Sprintf("%s:%s:", from.GetResourceType(), from.GetResource()))
+	if !ok {
+		return "", fmt.Errorf("unexpected entitlement id: %s", id)
+	}
+	return fmt.Sprintf("%s:%s:%s", to.GetResourceType(), to.GetResource(), slug), nil
+}
+
+func qualifyEntitlement(server string, e *v2.Entitlement) (*v2.Entitlement, error) {
+	var err error
+	ret := proto.Clone(e).(*v2.Entitlement)
+	ret.Resource = qualifyResource(server, e.Resource)
+	ret.Id, err = rewriteEntitlementID(e.Id, e.Resource.GetId(), ret.Resource.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func unqualifyEntitlement(server string, e *v2.Entitlement) (*v2.Entitlement, error) {
+	var err error
+	ret := proto.Clone(e).(*v2.Entitlement)
+	ret.Resource, err = unqualifyResource(server, e.Resource)
+	if err != nil {
+		return nil, err
+	}
+	ret.Id, err = rewriteEntitlementID(e.Id, e.Resource.GetId(), ret.Resource.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func qualifyGrant(server string, g *v2.Grant) (*v2.Grant, error) {
+	var err error
+	ret := proto.Clone(g).(*v2.Grant)
+	ret.Entitlement, err = qualifyEntitlement(server, g.Entitlement)
+	if err != nil {
+		return nil, err
+	}
+	ret.Principal = qualifyResource(server, g.Principal)
+	ret.Id = grTypes.NewGrantID(ret.Principal, ret.Entitlement)
+	return ret, nil
+}
+
+func unqualifyGrant(server string, g *v2.Grant) (*v2.Grant, error) {
+	var err error
+	ret := proto.Clone(g).(*v2.Grant)
+	ret.Entitlement, err = unqualifyEntitlement(server, g.Entitlement)
+	if err != nil {
+		return nil, err
+	}
+	ret.Principal, err = unqualifyResource(server, g.Principal)
+	if err != nil {
+		return nil, err
+	}
+	ret.Id = grTypes.NewGrantID(ret.Principal, ret.Entitlement)
+	return ret, nil
+}
+
+func qualifyGrants(server string, grants []*v2.Grant) ([]*v2.Grant, error) {
+	ret := make([]*v2.Grant, 0, len(grants))
+	for _, g := range grants {
+		qualified, err := qualifyGrant(server, g)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, qualified)
+	}
+	return ret, nil
+}
+
+// qualifyAnnotations qualifies the grants returned as annotations, e.g. by account creation.
+func qualifyAnnotations(server string, annos annotations.Annotations) (annotations.Annotations, error) {
+	ret := make(annotations.Annotations, 0, len(annos))
+	for _, a := range annos {
+		if !a.MessageIs(&v2.Grant{}) {
+			ret = append(ret, a)
+			continue
+		}
+
+		g := &v2.Grant{}
+		err := a.UnmarshalTo(g)
+		if err != nil {
+			return nil, err
+		}
+		g, err = qualifyGrant(server, g)
+		if err != nil {
+			return nil, err
+		}
+		qualified, err := anypb.New(g)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, qualified)
+	}
+	return ret, nil
+}
+
+// serverRouter routes the calls for a resource type to the syncer of the server a resource belongs to.
+// Resource IDs are qualified with the server name on the way out and unqualified on the way in,
+// so the syncers of each server work as they do in single-server mode.
+type serverRouter struct {
+	resourceType *v2.ResourceType
+	servers      []string
+	syncers      map[string]connectorbuilder.ResourceSyncer
+}
+
+func (r *serverRouter) ResourceType(ctx context.Context) *v2.ResourceType {
+	return r.resourceType
+}
+
+// route returns the server a resource belongs to and the syncer of that server.
+func (r *serverRouter) route(id *v2.ResourceId) (string, connectorbuilder.ResourceSyncer, error) {
+	if id == nil {
+		return "", nil, fmt.Errorf("a parent resource is required to find the server of a %s", r.resourceType.Id)
+	}
+
+	server, err := serverFromID(id.Resource)
+	if err != nil {
+		return "", nil, err
+	}
+
+	syncer, ok := r.syncers[server]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown server %s", server)
+	}
+
+	return server, syncer, nil
+}
+
+func (r *serverRouter) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// Only the server resources are listed without a parent, one per server.
+	if parentResourceID == nil {
+		var ret []*v2.Resource
+		for _, server := range r.servers {
+			token := ""
+			for {
+				resources, nextPageToken, _, err := r.syncers[server].List(ctx, nil, &pagination.Token{Size: pToken.Size, Token: token})
+				if err != nil {
+					return nil, "", nil, fmt.Errorf("server %s: %w", server, err)
+				}
+				for _, res := range resources {
+					ret = append(ret, qualifyResource(server, res))
+				}
+				if nextPageToken == "" {
+					break
+				}
+				token = nextPageToken
+			}
+		}
+		return ret, "", nil, nil
+	}
+
+	server, syncer, err := r.route(parentResourceID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	parentID, err := unqualifyResourceID(server, parentResourceID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resources, nextPageToken, annos, err := syncer.List(ctx, parentID, pToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	ret := make([]*v2.Resource, 0, len(resources))
+	for _, res := range resources {
+		ret = append(ret, qualifyResource(server, res))
+	}
+
+	return ret, nextPageToken, annos, nil
+}
+
+func (r *serverRouter) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	server, syncer, err := r.route(resource.Id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	inner, err := unqualifyResource(server, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entitlements, nextPageToken, annos, err := syncer.Entitlements(ctx, inner, pToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	ret := make([]*v2.Entitlement, 0, len(entitlements))
+	for _, e := range entitlements {
+		qualified, err := qualifyEntitlement(server, e)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, qualified)
+	}
+
+	return ret, nextPageToken, annos, nil
+}
+
+func (r *serverRouter) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	server, syncer, err := r.route(resource.Id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	inner, err := unqualifyResource(server, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	grants, nextPageToken, annos, err := syncer.Grants(ctx, inner, pToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	ret, err := qualifyGrants(server, grants)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return ret, nextPageToken, annos, nil
+}
+
+type routedProvisioner struct {
+	r *serverRouter
+}
+
+func (p routedProvisioner) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	server, syncer, err := p.r.route(entitlement.Resource.GetId())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	principalServer, err := serverFromID(principal.GetId().GetResource())
+	if err != nil {
+		return nil, nil, err
+	}
+	if principalServer != server {
+		return nil, nil, fmt.Errorf("an entitlement of server %s cannot be granted to a principal of server %s", server, principalServer)
+	}
+
+	innerPrincipal, err := unqualifyResource(server, principal)
+	if err != nil {
+		return nil, nil, err
+	}
+	innerEntitlement, err := unqualifyEntitlement(server, entitlement)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grants, annos, err := syncer.(connectorbuilder.ResourceProvisionerV2).Grant(ctx, innerPrincipal, innerEntitlement)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret, err := qualifyGrants(server, grants)
+	if err != nil {
+		return nil, nil, err
+	}
+	annos, err = qualifyAnnotations(server, annos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ret, annos, nil
+}
+
+func (p routedProvisioner) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	server, syncer, err := p.r.route(grant.GetEntitlement().GetResource().GetId())
+	if err != nil {
+		return nil, err
+	}
+	inner, err := unqualifyGrant(server, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	return syncer.(connectorbuilder.ResourceProvisionerV2).Revoke(ctx, inner)
+}
+
+type routedCreator struct {
+	r *serverRouter
+}
+
+// Create creates the resource on the server of its parent resource.
+func (c routedCreator) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	server, syncer, err := c.r.route(resource.GetParentResourceId())
+	if err != nil {
+		return nil, nil, err
+	}
+	inner, err := unqualifyResource(server, resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created, annos, err := syncer.(connectorbuilder.ResourceManager).Create(ctx, inner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annos, err = qualifyAnnotations(server, annos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return qualifyResource(server, created), annos, nil
+}
+
+type routedDeleter struct {
+	r *serverRouter
+}
+
+func (d routedDeleter) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	server, syncer, err := d.r.route(resourceId)
+	if err != nil {
+		return nil, err
+	}
+	inner, err := unqualifyResourceID(server, resourceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return syncer.(connectorbuilder.ResourceDeleter).Delete(ctx, inner)
+}
+
+type routedAccountManager struct {
+	r *serverRouter
+}
+
+// CreateAccount creates the login on the server named by the 'server' field of the account profile.
+func (m routedAccountManager) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	credentialOptions *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	server := accountInfo.GetProfile().GetFields()[serverArgName].GetStringValue()
+	if server == "" {
+		return nil, nil, nil, fmt.Errorf("missing required %s field", serverArgName)
+	}
+	syncer, ok := m.r.syncers[server]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unknown server %s", server)
+	}
+
+	resp, plaintexts, annos, err := syncer.(connectorbuilder.AccountManager).CreateAccount(ctx, accountInfo, credentialOptions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	switch r := resp.(type) {
+	case *v2.CreateAccountResponse_SuccessResult:
+		r.Resource = qualifyResource(server, r.Resource)
+	case *v2.CreateAccountResponse_ActionRequiredResult:
+		r.Resource = qualifyResource(server, r.Resource)
+	}
+
+	annos, err = qualifyAnnotations(server, annos)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return resp, plaintexts, annos, nil
+}
+
+func (m routedAccountManager) CreateAccountCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return m.r.syncers[m.r.servers[0]].(connectorbuilder.AccountManager).CreateAccountCapabilityDetails(ctx)
+}
+
+type routedCredentialManager struct {
+	r *serverRouter
+}
+
+func (m routedCredentialManager) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	server, syncer, err := m.r.route(resourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+	inner, err := unqualifyResourceID(server, resourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return syncer.(connectorbuilder.CredentialManager).Rotate(ctx, inner, credentialOptions)
+}
+
+func (m routedCredentialManager) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return m.r.syncers[m.r.servers[0]].(connectorbuilder.CredentialManager).RotateCapabilityDetails(ctx)
+}
+
+// The routed syncers expose the same capabilities as the syncers they route to,
+// which is how the connector builder finds out what a resource type supports.
+type (
+	routedProvisionerSyncer struct {
+		*serverRouter
+		routedProvisioner
+	}
+	routedResourceManagerSyncer struct {
+		*serverRouter
+		routedProvisioner
+		routedCreator
+		routedDeleter
+	}
+	routedCredentialResourceManagerSyncer struct {
+		*serverRouter
+		routedProvisioner
+		routedCreator
+		routedDeleter
+		routedCredentialManager
+	}
+	routedAccountManagerSyncer struct {
+		*serverRouter
+		routedProvisioner
+		routedDeleter
+		routedAccountManager
+		routedCredentialManager
+	}
+)
+
+// newServerRouter returns a syncer routing to the syncers of each server, which all have the same resource type.
+func newServerRouter(servers []string, syncers map[string]connectorbuilder.ResourceSyncer) (connectorbuilder.ResourceSyncer, error) {
+	sample := syncers[servers[0]]
+	r := &serverRouter{
+		resourceType: sample.ResourceType(context.Background()),
+		servers:      servers,
+		syncers:      syncers,
+	}
+
+	_, provisions := sample.(connectorbuilder.ResourceProvisionerV2)
+	_, creates := sample.(connectorbuilder.ResourceManager)
+	_, deletes := sample.(connectorbuilder.ResourceDeleter)
+	_, createsAccounts := sample.(connectorbuilder.AccountManager)
+	_, rotates := sample.(connectorbuilder.CredentialManager)
+
+	switch {
+	case !provisions && !deletes && !createsAccounts && !rotates:
+		return r, nil
+	case provisions && !deletes && !createsAccounts && !rotates:
+		return &routedProvisionerSyncer{r, routedProvisioner{r}}, nil
+	case provisions && creates && !createsAccounts && !rotates:
+		return &routedResourceManagerSyncer{r, routedProvisioner{r}, routedCreator{r}, routedDeleter{r}}, nil
+	case provisions && creates && !createsAccounts && rotates:
+		return &routedCredentialResourceManagerSyncer{r, routedProvisioner{r}, routedCreator{r}, routedDeleter{r}, routedCredentialManager{r}}, nil
+	case provisions && deletes && !creates && createsAccounts && rotates:
+		return &routedAccountManagerSyncer{r, routedProvisioner{r}, routedDeleter{r}, routedAccountManager{r}, routedCredentialManager{r}}, nil
+	default:
+		return nil, fmt.Errorf("resource type %s has capabilities that cannot be routed to multiple servers", r.resourceType.Id)
+	}
+}
+
+// newServerActionManager merges the actions of each server. Every action takes a 'server' argument naming the server
+// it runs on. String arguments qualified with that server, e.g. a login ID copied from a resource, are unqualified.
+func newServerActionManager(servers []string, managers map[string]*actionManager) *actionManager {
+	m := &actionManager{
+		actions: make(map[string]*action),
+	}
+
+	for name, a := range managers[servers[0]].actions {
+		schema := proto.Clone(a.schema).(*v2.BatonActionSchema)
+		schema.Arguments = append([]*config.Field{
+			stringActionField(serverArgName, "Server", "The name of the server to run the action on.", true),
+		}, schema.Arguments...)
+
+		m.register(schema, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+			server, err := actionStringArg(args, serverArgName, true)
+			if err != nil {
+				return nil, err
+			}
+			sm, ok := managers[server]
+			if !ok {
+				return nil, fmt.Errorf("unknown server %s", server)
+			}
+
+			inner := proto.Clone(args).(*structpb.Struct)
+			for key, v := range inner.GetFields() {
+				if s, ok := strings.CutPrefix(v.GetStringValue(), server+serverIDSeparator); ok {
+					inner.Fields[key] = structpb.NewStringValue(s)
+				}
+			}
+
+			return sm.actions[name].handler(ctx, inner)
+		})
+	}
+
+	return m
+}
@@ -0,0 +1,372 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// objectSyncer syncs the objects of a schema (tables, views or routines) and the permissions granted on them.
+type objectSyncer struct {
+	resourceType *v2.ResourceType
+	client       *mssqldb.Client
+	objectTypes  []string
+	permissions  map[string]string
+	// columnPermissions are exposed for each column of the object when set.
+	columnPermissions map[string]string
+	patterns          *mssqldb.NamePatterns
+}
+
+func (d *objectSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
+	return d.resourceType
+}
+
+func (d *objectSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	if parentResourceID.ResourceType != resourceTypeSchema.Id {
+		return nil, "", nil, fmt.Errorf("%s resources must have a schema as the parent resource", d.resourceType.Id)
+	}
+
+	dbName, schemaID, err := parseSchemaResourceID(parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	objects, nextPageToken, err := d.client.ListObjects(
+		ctx,
+		dbName,
+		schemaID,
+		d.objectTypes,
+		d.patterns,
+		&mssqldb.Pager{Token: pToken.Token, Size: pToken.Size},
+	)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var ret []*v2.Resource
+	for _, objectModel := range objects {
+		r, err := resource.NewResource(
+			fmt.Sprintf("%s.%s (%s)", objectModel.SchemaName, objectModel.Name, dbName),
+			d.ResourceType(ctx),
+			fmt.Sprintf("%s:%d", dbName, objectModel.ID),
+			resource.WithParentResourceID(parentResourceID),
+			resource.WithDescription(objectModel.TypeDesc),
+		)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *objectSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var ret []*v2.Entitlement
+
+	// The object-level entitlements are returned with the first page, the remaining pages list the column entitlements.
+	if pToken.Token == "" {
+		for key, name := range d.permissions {
+			grantSlug := fmt.Sprintf("%s (With Grant)", name)
+			ret = append(ret,
+				&v2.Entitlement{
+					Id:          enTypes.NewEntitlementID(resource, key),
+					DisplayName: name,
+					Slug:        name,
+					Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+					Resource:    resource,
+					GrantableTo: []*v2.ResourceType{resourceTypeUser},
+				},
+				&v2.Entitlement{
+					Id:          enTypes.NewEntitlementID(resource, key+"-grant"),
+					DisplayName: grantSlug,
+					Slug:        grantSlug,
+					Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+					Resource:    resource,
+					GrantableTo: []*v2.ResourceType{resourceTypeUser},
+				})
+		}
+	}
+
+	if d.columnPermissions == nil {
+		return ret, "", nil, nil
+	}
+
+	dbName, objectID, err := parseObjectResourceID(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	columns, nextPageToken, err := d.client.ListColumns(ctx, dbName, objectID, &mssqldb.Pager{Token: pToken.Token, Size: pToken.Size})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, column := range columns {
+		for key, name := range d.columnPermissions {
+			if key == "UMSK" && !column.IsMasked {
+				continue
+			}
+
+			name := fmt.Sprintf("%s (%s)", name, column.Name)
+			for _, state := range []string{permissionStateGrant, permissionStateGrantWithGrant, permissionStateDeny} {
+				slug, _ := permissionSlug(columnPermissionSlug(key, column.Name), state)
+				displayName := name
+				switch state {
+				case permissionStateGrantWithGrant:
+					displayName = fmt.Sprintf("%s (With Grant)", name)
+				case permissionStateDeny:
+					displayName = fmt.Sprintf("%s (Deny)", name)
+				}
+				ret = append(ret, &v2.Entitlement{
+					Id:          enTypes.NewEntitlementID(resource, slug),
+					DisplayName: displayName,
+					Slug:        displayName,
+					Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+					Resource:    resource,
+					GrantableTo: []*v2.ResourceType{resourceTypeUser},
+				})
+			}
+		}
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *objectSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var ret []*v2.Grant
+
+	l := ctxzap.Extract(ctx)
+
+	dbName, objectID, err := parseObjectResourceID(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	principalPerms, nextPageToken, err := d.client.ListObjectPermissions(ctx, dbName, objectID, &mssqldb.Pager{Size: pToken.Size, Token: pToken.Token})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, p := range principalPerms {
+		perms := strings.Split(p.Permissions, ",")
+		for _, perm := range perms {
+			perm = strings.TrimSpace(perm)
+
+			permissions := d.permissions
+			if p.ColumnName != "" {
+				permissions = d.columnPermissions
+			}
+			if _, ok := permissions[perm]; !ok {
+				continue
+			}
+
+			rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
+			if err != nil {
+				l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+				continue
+			}
+
+			resourceID, err := databasePrincipalResourceID(ctx, d.client, dbName, p.PrincipalID, p.PrincipalName, rt)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if resourceID == nil {
+				continue
+			}
+
+			if p.ColumnName != "" {
+				perm = columnPermissionSlug(perm, p.ColumnName)
+			}
+
+			slug, ok := permissionSlug(perm, p.State)
+			if !ok {
+				continue
+			}
+			ret = append(ret, grTypes.NewGrant(resource, slug, resourceID))
+		}
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *objectSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if resource.Id.ResourceType != resourceTypeUser.Id {
+		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+	}
+
+	// table:baton_test:245575913:SL or table:baton_test:245575913:SL(column)-grant
+	splitId := strings.SplitN(entitlement.Id, ":", 4)
+	if len(splitId) != 4 {
+		return nil, nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	objectID := splitId[2]
+	permission, column, state, isColumn := parseObjectPermissionSlug(splitId[3])
+
+	permissions := d.permissions
+	if isColumn {
+		permissions = d.columnPermissions
+	}
+	if _, ok := permissions[permission]; !ok || (state == permissionStateDeny && !isColumn) {
+		return nil, nil, fmt.Errorf("permission %s is not supported on %s resources", splitId[3], d.resourceType.Id)
+	}
+
+	object, err := d.client.GetObject(ctx, dbName, objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ensureDatabaseUser(ctx, d.client, dbName, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case isColumn && state == permissionStateDeny:
+		err = d.client.DenyPermissionOnColumn(ctx, permission, dbName, object.SchemaName, object.Name, column, user.Name)
+	case isColumn:
+		err = d.client.GrantPermissionOnColumn(ctx, permission, dbName, object.SchemaName, object.Name, column, user.Name, state == permissionStateGrantWithGrant)
+	default:
+		err = d.client.GrantPermissionOnObject(ctx, permission, dbName, object.SchemaName, object.Name, object.Type, user.Name, state == permissionStateGrantWithGrant)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grTypes.NewGrant(entitlement.Resource, splitId[3], &v2.ResourceId{
+		Resource:     user.ID,
+		ResourceType: resourceTypeUser.Id,
+	})
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+func (d *objectSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if grant.Principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("resource type %s is not supported for revoking", grant.Principal.Id.ResourceType)
+	}
+
+	// table:baton_test:245575913:SL or table:baton_test:245575913:SL(column)-grant
+	splitId := strings.SplitN(grant.Entitlement.Id, ":", 4)
+	if len(splitId) != 4 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	objectID := splitId[2]
+	permission, column, state, isColumn := parseObjectPermissionSlug(splitId[3])
+
+	object, err := d.client.GetObject(ctx, dbName, objectID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, grant.Principal.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if isColumn {
+		err = d.client.RevokePermissionOnColumn(ctx, permission, dbName, object.SchemaName, object.Name, column, user.Name, state == permissionStateGrantWithGrant)
+	} else {
+		err = d.client.RevokePermissionOnObject(ctx, permission, dbName, object.SchemaName, object.Name, object.Type, user.Name, state == permissionStateGrantWithGrant)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("revoked permission", zap.String("permission", permission), zap.String("user", user.Name), zap.String("object", object.Name))
+	return nil, nil
+}
+
+// parseObjectResourceID splits an object resource ID into the database name and object ID.
+func parseObjectResourceID(id string) (string, string, error) {
+	idParts := strings.Split(id, ":")
+	if len(idParts) != 2 {
+		return "", "", fmt.Errorf("invalid object id: %s", id)
+	}
+
+	return idParts[0], idParts[1], nil
+}
+
+// columnPermissionSlug returns the entitlement slug for a permission on a single column, e.g. SL(ssn).
+func columnPermissionSlug(permission string, column string) string {
+	return fmt.Sprintf("%s(%s)", permission, column)
+}
+
+// parseColumnPermissionSlug splits a column entitlement slug into the permission and the column name.
+// It returns false if the slug is not for a column-level permission.
+func parseColumnPermissionSlug(slug string) (string, string, bool) {
+	idx := strings.Index(slug, "(")
+	if idx <= 0 || !strings.HasSuffix(slug, ")") {
+		return "", "", false
+	}
+
+	return slug[:idx], slug[idx+1 : len(slug)-1], true
+}
+
+// parseObjectPermissionSlug splits the slug of an object or column entitlement into the permission,
+// the column name when it is for a column-level permission, and the permission state.
+func parseObjectPermissionSlug(slug string) (string, string, string, bool) {
+	permission, state := parsePermissionSlug(slug)
+	columnPermission, column, ok := parseColumnPermissionSlug(permission)
+	if !ok {
+		return permission, "", state, false
+	}
+
+	return columnPermission, column, state, true
+}
+
+func newTableSyncer(ctx context.Context, c *mssqldb.Client, patterns *mssqldb.NamePatterns) *objectSyncer {
+	return &objectSyncer{
+		resourceType:      resourceTypeTable,
+		client:            c,
+		objectTypes:       mssqldb.TableObjectTypes,
+		permissions:       mssqldb.TablePermissions,
+		columnPermissions: mssqldb.ColumnPermissions,
+		patterns:          patterns,
+	}
+}
+
+func newViewSyncer(ctx context.Context, c *mssqldb.Client, patterns *mssqldb.NamePatterns) *objectSyncer {
+	return &objectSyncer{
+		resourceType: resourceTypeView,
+		client:       c,
+		objectTypes:  mssqldb.ViewObjectTypes,
+		permissions:  mssqldb.TablePermissions,
+		patterns:     patterns,
+	}
+}
+
+func newRoutineSyncer(ctx context.Context, c *mssqldb.Client, patterns *mssqldb.NamePatterns) *objectSyncer {
+	return &objectSyncer{
+		resourceType: resourceTypeRoutine,
+		client:       c,
+		objectTypes:  mssqldb.RoutineObjectTypes,
+		permissions:  mssqldb.RoutinePermissions,
+		patterns:     patterns,
+	}
+}
@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const ownerSlug = "owner"
+
+// errRevokeOwner is returned when revoking ownership: a securable always has an owner, so ownership can only be transferred.
+var errRevokeOwner = errors.New("ownership cannot be revoked, grant the owner entitlement to another user to transfer it")
+
+func ownerEntitlement(resource *v2.Resource) *v2.Entitlement {
+	return enTypes.NewPermissionEntitlement(resource, ownerSlug,
+		enTypes.WithDisplayName("Owner"),
+		enTypes.WithGrantableTo(resourceTypeUser),
+	)
+}
+
+// serverOwnerGrant returns the owner grant for a database or server role owned by a server principal.
+// It returns nil if the owner is excluded by the principal filter.
+func serverOwnerGrant(ctx context.Context, c *mssqldb.Client, resource *v2.Resource, owner *mssqldb.OwnerModel) (*v2.Grant, error) {
+	l := ctxzap.Extract(ctx)
+
+	rt, err := resourceTypeFromServerPrincipal(owner.Type)
+	if err != nil {
+		l.Error("unexpected owner principal type", zap.String("principal_type", owner.Type))
+		return nil, nil
+	}
+
+	if !c.PrincipalAllowed(owner.Name) {
+		return nil, nil
+	}
+
+	return grTypes.NewGrant(resource, ownerSlug, &v2.ResourceId{
+		ResourceType: rt.Id,
+		Resource:     strconv.FormatInt(owner.ID, 10),
+	}), nil
+}
+
+// databaseOwnerGrant returns the owner grant for a schema or database role owned by a database principal.
+// It returns nil if the owner is excluded by the principal filter.
+func databaseOwnerGrant(ctx context.Context, c *mssqldb.Client, dbName string, resource *v2.Resource, owner *mssqldb.OwnerModel) (*v2.Grant, error) {
+	l := ctxzap.Extract(ctx)
+
+	rt, err := resourceTypeFromDatabasePrincipal(owner.Type)
+	if err != nil {
+		l.Error("unexpected owner principal type", zap.String("principal_type", owner.Type))
+		return nil, nil
+	}
+
+	principalID, err := databasePrincipalResourceID(ctx, c, dbName, owner.ID, owner.Name, rt)
+	if err != nil {
+		return nil, err
+	}
+	if principalID == nil {
+		return nil, nil
+	}
+
+	return grTypes.NewGrant(resource, ownerSlug, principalID), nil
+}
+
+// roleOwnerFromResource returns the owner requested for a role being created, read from the 'owner' field of its role profile.
+// An empty owner leaves the role owned by the connector's principal.
+func roleOwnerFromResource(r *v2.Resource) (string, error) {
+	var owner string
+	if roleTrait, err := resource.GetRoleTrait(r); err == nil {
+		owner, _ = resource.GetProfileStringValue(roleTrait.GetProfile(), "owner")
+	}
+	if strings.ContainsAny(owner, "[]\"';") {
+		return "", fmt.Errorf("invalid characters in role owner")
+	}
+
+	return owner, nil
+}
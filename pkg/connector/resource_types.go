@@ -35,4 +35,32 @@ var (
 		DisplayName: "Database Role",
 		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
 	}
+	// Database users are the users of a database. Users mapped to a server login only carry the permissions granted on them.
+	resourceTypeDatabaseUser = &v2.ResourceType{
+		Id:          mssqldb.DatabaseUserType,
+		DisplayName: "Database User",
+		Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+	}
+	// The random password of a new application role is not returned, so it must be rotated before sp_setapprole can activate the role.
+	resourceTypeApplicationRole = &v2.ResourceType{
+		Id:          mssqldb.ApplicationRoleType,
+		DisplayName: "Application Role",
+		Description: "Application roles of a database. A new application role cannot be activated until its password is rotated.",
+	}
+	resourceTypeSchema = &v2.ResourceType{
+		Id:          mssqldb.SchemaType,
+		DisplayName: "Schema",
+	}
+	resourceTypeTable = &v2.ResourceType{
+		Id:          mssqldb.TableType,
+		DisplayName: "Table",
+	}
+	resourceTypeView = &v2.ResourceType{
+		Id:          mssqldb.ViewType,
+		DisplayName: "View",
+	}
+	resourceTypeRoutine = &v2.ResourceType{
+		Id:          mssqldb.RoutineType,
+		DisplayName: "Routine",
+	}
 )
@@ -0,0 +1,274 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+type schemaSyncer struct {
+	resourceType          *v2.ResourceType
+	client                *mssqldb.Client
+	syncObjectPermissions bool
+}
+
+func (d *schemaSyncer) ResourceType(ctx context.Context) *v2.ResourceType {
+	return d.resourceType
+}
+
+func (d *schemaSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	if parentResourceID.ResourceType != resourceTypeDatabase.Id {
+		return nil, "", nil, fmt.Errorf("schemas must have a database as the parent resource")
+	}
+
+	dbID, err := strconv.ParseInt(parentResourceID.Resource, 10, 64)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	db, err := d.client.GetDatabase(ctx, dbID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	schemas, nextPageToken, err := d.client.ListSchemas(ctx, db.Name, &mssqldb.Pager{Token: pToken.Token, Size: pToken.Size})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resourceOpts := []resource.ResourceOption{resource.WithParentResourceID(parentResourceID)}
+	if d.syncObjectPermissions {
+		resourceOpts = append(resourceOpts, resource.WithAnnotation(
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeTable.Id},
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeView.Id},
+			&v2.ChildResourceType{ResourceTypeId: resourceTypeRoutine.Id},
+		))
+	}
+
+	var ret []*v2.Resource
+	for _, schemaModel := range schemas {
+		r, err := resource.NewResource(
+			fmt.Sprintf("%s (%s)", schemaModel.Name, db.Name),
+			d.ResourceType(ctx),
+			fmt.Sprintf("%s:%d", db.Name, schemaModel.ID),
+			resourceOpts...,
+		)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, r)
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *schemaSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var ret []*v2.Entitlement
+
+	ret = append(ret, ownerEntitlement(resource))
+	for key, name := range mssqldb.SchemaPermissions {
+		grantSlug := fmt.Sprintf("%s (With Grant)", name)
+		ret = append(ret,
+			&v2.Entitlement{
+				Id:          enTypes.NewEntitlementID(resource, key),
+				DisplayName: name,
+				Slug:        name,
+				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+				Resource:    resource,
+				GrantableTo: []*v2.ResourceType{resourceTypeUser},
+			},
+			&v2.Entitlement{
+				Id:          enTypes.NewEntitlementID(resource, key+"-grant"),
+				DisplayName: grantSlug,
+				Slug:        grantSlug,
+				Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+				Resource:    resource,
+				GrantableTo: []*v2.ResourceType{resourceTypeUser},
+			})
+	}
+
+	return ret, "", nil, nil
+}
+
+func (d *schemaSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var ret []*v2.Grant
+
+	l := ctxzap.Extract(ctx)
+
+	dbName, schemaID, err := parseSchemaResourceID(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if pToken.Token == "" {
+		owner, err := d.client.GetSchemaOwner(ctx, dbName, schemaID)
+		if err != nil && !errors.Is(err, mssqldb.ErrNoOwner) {
+			return nil, "", nil, err
+		}
+		if owner != nil {
+			ownerGrant, err := databaseOwnerGrant(ctx, d.client, dbName, resource, owner)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if ownerGrant != nil {
+				ret = append(ret, ownerGrant)
+			}
+		}
+	}
+
+	principalPerms, nextPageToken, err := d.client.ListSchemaPermissions(ctx, dbName, schemaID, &mssqldb.Pager{Size: pToken.Size, Token: pToken.Token})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, p := range principalPerms {
+		perms := strings.Split(p.Permissions, ",")
+		for _, perm := range perms {
+			perm = strings.TrimSpace(perm)
+			if _, ok := mssqldb.SchemaPermissions[perm]; !ok {
+				continue
+			}
+
+			rt, err := resourceTypeFromDatabasePrincipal(p.PrincipalType)
+			if err != nil {
+				l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+				continue
+			}
+
+			resourceID, err := databasePrincipalResourceID(ctx, d.client, dbName, p.PrincipalID, p.PrincipalName, rt)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if resourceID == nil {
+				continue
+			}
+
+			slug, ok := permissionSlug(perm, p.State)
+			if !ok {
+				continue
+			}
+			ret = append(ret, grTypes.NewGrant(resource, slug, resourceID))
+		}
+	}
+
+	return ret, nextPageToken, nil, nil
+}
+
+func (d *schemaSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if resource.Id.ResourceType != resourceTypeUser.Id {
+		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+	}
+
+	// schema:baton_test:5:SL
+	splitId := strings.Split(entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	schemaID := splitId[2]
+	permission, state := parsePermissionSlug(splitId[3])
+
+	schema, err := d.client.GetSchema(ctx, dbName, schemaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = ensureDatabaseUser(ctx, d.client, dbName, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if permission == ownerSlug {
+		err = d.client.ChangeSchemaOwner(ctx, dbName, schema.Name, user.Name)
+	} else {
+		err = d.client.GrantPermissionOnSchema(ctx, permission, dbName, schema.Name, user.Name, state == permissionStateGrantWithGrant)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grTypes.NewGrant(entitlement.Resource, splitId[3], &v2.ResourceId{
+		Resource:     user.ID,
+		ResourceType: resourceTypeUser.Id,
+	})
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+func (d *schemaSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if grant.Principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("resource type %s is not supported for revoking", grant.Principal.Id.ResourceType)
+	}
+
+	// schema:baton_test:5:SL
+	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) != 4 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
+
+	dbName := splitId[1]
+	schemaID := splitId[2]
+	permission, state := parsePermissionSlug(splitId[3])
+	if permission == ownerSlug {
+		return nil, errRevokeOwner
+	}
+
+	schema, err := d.client.GetSchema(ctx, dbName, schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, grant.Principal.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.client.RevokePermissionOnSchema(ctx, permission, dbName, schema.Name, user.Name, state == permissionStateGrantWithGrant)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("revoked permission", zap.String("permission", permission), zap.String("user", user.Name), zap.String("schema", schema.Name))
+	return nil, nil
+}
+
+// parseSchemaResourceID splits a schema resource ID into the database name and schema ID.
+func parseSchemaResourceID(id string) (string, string, error) {
+	idParts := strings.Split(id, ":")
+	if len(idParts) != 2 {
+		return "", "", fmt.Errorf("invalid schema id: %s", id)
+	}
+
+	return idParts[0], idParts[1], nil
+}
+
+func newSchemaSyncer(ctx context.Context, c *mssqldb.Client, syncObjectPermissions bool) *schemaSyncer {
+	return &schemaSyncer{
+		resourceType:          resourceTypeSchema,
+		client:                c,
+		syncObjectPermissions: syncObjectPermissions,
+	}
+}
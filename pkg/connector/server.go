@@ -14,6 +14,8 @@ import (
 	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 var serverPermissions = map[string]string{
@@ -53,6 +55,9 @@ var serverPermissions = map[string]string{
 	"XU":   "Unsafe Assembly",
 }
 
+// serverPermissionGrantableTo are the principals server permissions can be provisioned to.
+var serverPermissionGrantableTo = []*v2.ResourceType{resourceTypeUser, resourceTypeGroup, resourceTypeServerRole}
+
 type serverSyncer struct {
 	resourceType *v2.ResourceType
 	client       *mssqldb.Client
@@ -92,6 +97,11 @@ func (d *serverSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId
 func (d *serverSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var ret []*v2.Entitlement
 
+	// Azure SQL Database has no server permissions.
+	if d.client.IsAzureSQLDatabase() {
+		return nil, "", nil, nil
+	}
+
 	for key, name := range serverPermissions {
 		ret = append(ret, &v2.Entitlement{
 			Id:          enTypes.NewEntitlementID(resource, key),
@@ -99,6 +109,7 @@ func (d *serverSyncer) Entitlements(ctx context.Context, resource *v2.Resource,
 			Slug:        name,
 			Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
 			Resource:    resource,
+			GrantableTo: serverPermissionGrantableTo,
 		})
 		ret = append(ret, &v2.Entitlement{
 			Id:          enTypes.NewEntitlementID(resource, key+"-grant"),
@@ -106,6 +117,15 @@ func (d *serverSyncer) Entitlements(ctx context.Context, resource *v2.Resource,
 			Slug:        fmt.Sprintf("%s (With Grant)", name),
 			Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
 			Resource:    resource,
+			GrantableTo: serverPermissionGrantableTo,
+		})
+		ret = append(ret, &v2.Entitlement{
+			Id:          enTypes.NewEntitlementID(resource, key+"-deny"),
+			DisplayName: fmt.Sprintf("%s (Deny)", name),
+			Slug:        fmt.Sprintf("%s (Deny)", name),
+			Purpose:     v2.Entitlement_PURPOSE_VALUE_PERMISSION,
+			Resource:    resource,
+			GrantableTo: serverPermissionGrantableTo,
 		})
 	}
 
@@ -121,6 +141,10 @@ func (d *serverSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken
 	}
 
 	for _, p := range principalPerms {
+		if !d.client.PrincipalAllowed(p.PrincipalName) {
+			continue
+		}
+
 		perms := strings.Split(p.Permissions, ",")
 		for _, perm := range perms {
 			perm = strings.TrimSpace(perm)
@@ -129,18 +153,14 @@ func (d *serverSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken
 				if err != nil {
 					return nil, "", nil, err
 				}
-				switch p.State {
-				case "G":
-					ret = append(ret, grTypes.NewGrant(resource, perm, &v2.ResourceId{
-						ResourceType: rt.Id,
-						Resource:     strconv.FormatInt(p.PrincipalID, 10),
-					}))
-				case "W":
-					ret = append(ret, grTypes.NewGrant(resource, perm+"-grant", &v2.ResourceId{
-						ResourceType: rt.Id,
-						Resource:     strconv.FormatInt(p.PrincipalID, 10),
-					}))
+				slug, ok := permissionSlug(perm, p.State)
+				if !ok {
+					continue
 				}
+				ret = append(ret, grTypes.NewGrant(resource, slug, &v2.ResourceId{
+					ResourceType: rt.Id,
+					Resource:     strconv.FormatInt(p.PrincipalID, 10),
+				}))
 			}
 		}
 	}
@@ -148,6 +168,67 @@ func (d *serverSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return ret, nextPageToken, nil, nil
 }
 
+func (d *serverSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	// server:MSSQLSERVER:VWSS-grant
+	splitId := strings.Split(entitlement.Id, ":")
+	if len(splitId) < 3 {
+		return nil, nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
+	}
+
+	slug := splitId[len(splitId)-1]
+	permission, state := parsePermissionSlug(slug)
+	if _, ok := serverPermissions[permission]; !ok {
+		return nil, nil, fmt.Errorf("permission %s is not supported", permission)
+	}
+
+	principalName, err := serverPrincipalName(ctx, d.client, resource.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch state {
+	case permissionStateDeny:
+		err = d.client.DenyPermissionOnServer(ctx, permission, principalName)
+	default:
+		err = d.client.GrantPermissionOnServer(ctx, permission, principalName, state == permissionStateGrantWithGrant)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newGrant := grTypes.NewGrant(entitlement.Resource, slug, resource.Id)
+
+	return []*v2.Grant{newGrant}, nil, nil
+}
+
+func (d *serverSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	// server:MSSQLSERVER:VWSS-grant
+	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) < 3 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
+
+	permission, state := parsePermissionSlug(splitId[len(splitId)-1])
+	if _, ok := serverPermissions[permission]; !ok {
+		return nil, fmt.Errorf("permission %s is not supported", permission)
+	}
+
+	principalName, err := serverPrincipalName(ctx, d.client, grant.Principal.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.client.RevokePermissionOnServer(ctx, permission, principalName, state == permissionStateGrantWithGrant)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Debug("revoked server permission", zap.String("permission", permission), zap.String("principal", principalName))
+	return nil, nil
+}
+
 func newServerSyncer(ctx context.Context, c *mssqldb.Client) *serverSyncer {
 	return &serverSyncer{
 		resourceType: resourceTypeServer,
@@ -0,0 +1,229 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// serverRolePermissions are the permissions synced on server roles. IMPERSONATE only applies to logins.
+var serverRolePermissions = map[string]string{
+	"AL": "Alter",
+	"CL": "Control",
+	"TO": "Take Ownership",
+	"VW": "View Definition",
+}
+
+// serverPrincipalPermissionEntitlements returns the entitlements for permissions granted on a login or server role.
+// Azure SQL Database has no permissions on server principals, so there are none there.
+func serverPrincipalPermissionEntitlements(c *mssqldb.Client, resource *v2.Resource, permissions map[string]string) []*v2.Entitlement {
+	var ret []*v2.Entitlement
+
+	if c.IsAzureSQLDatabase() {
+		return nil
+	}
+
+	for key, name := range permissions {
+		ret = append(ret,
+			enTypes.NewPermissionEntitlement(resource, key,
+				enTypes.WithDisplayName(name),
+				enTypes.WithGrantableTo(serverPermissionGrantableTo...),
+			),
+			enTypes.NewPermissionEntitlement(resource, key+permissionGrantOptionSuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (With Grant)", name)),
+				enTypes.WithGrantableTo(serverPermissionGrantableTo...),
+			),
+			enTypes.NewPermissionEntitlement(resource, key+permissionDenySuffix,
+				enTypes.WithDisplayName(fmt.Sprintf("%s (Deny)", name)),
+				enTypes.WithGrantableTo(serverPermissionGrantableTo...),
+			),
+		)
+	}
+
+	return ret
+}
+
+// serverPrincipalPermissionGrants returns a page of grants for the permissions granted on a login or server role.
+func serverPrincipalPermissionGrants(
+	ctx context.Context,
+	c *mssqldb.Client,
+	resource *v2.Resource,
+	permissions map[string]string,
+	pager *mssqldb.Pager,
+) ([]*v2.Grant, string, error) {
+	l := ctxzap.Extract(ctx)
+
+	principalPerms, nextPageToken, err := c.ListServerPrincipalPermissions(ctx, resource.Id.Resource, pager)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var ret []*v2.Grant
+	for _, p := range principalPerms {
+		if !c.PrincipalAllowed(p.PrincipalName) {
+			continue
+		}
+
+		rt, err := resourceTypeFromServerPrincipal(p.PrincipalType)
+		if err != nil {
+			l.Error("unexpected principal type", zap.String("principal_type", p.PrincipalType))
+			continue
+		}
+
+		for _, perm := range strings.Split(p.Permissions, ",") {
+			perm = strings.TrimSpace(perm)
+			if _, ok := permissions[perm]; !ok {
+				continue
+			}
+
+			slug, ok := permissionSlug(perm, p.State)
+			if !ok {
+				continue
+			}
+			ret = append(ret, grTypes.NewGrant(resource, slug, &v2.ResourceId{
+				ResourceType: rt.Id,
+				Resource:     strconv.FormatInt(p.PrincipalID, 10),
+			}))
+		}
+	}
+
+	return ret, nextPageToken, nil
+}
+
+// grantServerPrincipalPermission grants a permission on the login or server role the entitlement belongs to.
+func grantServerPrincipalPermission(
+	ctx context.Context,
+	c *mssqldb.Client,
+	principal *v2.Resource,
+	entitlement *v2.Entitlement,
+	permissions map[string]string,
+) ([]*v2.Grant, error) {
+	// user:261:IM-grant
+	splitId := strings.Split(entitlement.Id, ":")
+	if len(splitId) != 3 {
+		return nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
+	}
+
+	permission, state := parsePermissionSlug(splitId[2])
+	if _, ok := permissions[permission]; !ok {
+		return nil, fmt.Errorf("permission %s is not supported", permission)
+	}
+
+	class, securable, err := serverPrincipalSecurable(ctx, c, entitlement.Resource.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	principalName, err := serverPrincipalName(ctx, c, principal.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch state {
+	case permissionStateDeny:
+		err = c.DenyPermissionOnServerPrincipal(ctx, permission, class, securable, principalName)
+	default:
+		err = c.GrantPermissionOnServerPrincipal(ctx, permission, class, securable, principalName, state == permissionStateGrantWithGrant)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []*v2.Grant{grTypes.NewGrant(entitlement.Resource, splitId[2], principal.Id)}, nil
+}
+
+// revokeServerPrincipalPermission revokes a permission on the login or server role the grant's entitlement belongs to.
+func revokeServerPrincipalPermission(ctx context.Context, c *mssqldb.Client, grant *v2.Grant, permissions map[string]string) error {
+	// user:261:IM-grant
+	splitId := strings.Split(grant.Entitlement.Id, ":")
+	if len(splitId) != 3 {
+		return fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
+	}
+
+	permission, state := parsePermissionSlug(splitId[2])
+	if _, ok := permissions[permission]; !ok {
+		return fmt.Errorf("permission %s is not supported", permission)
+	}
+
+	class, securable, err := serverPrincipalSecurable(ctx, c, grant.Entitlement.Resource.Id)
+	if err != nil {
+		return err
+	}
+
+	principalName, err := serverPrincipalName(ctx, c, grant.Principal.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.RevokePermissionOnServerPrincipal(ctx, permission, class, securable, principalName, state == permissionStateGrantWithGrant)
+}
+
+// serverPrincipalSecurable returns how a login or server role is referenced as the securable of a GRANT statement.
+func serverPrincipalSecurable(ctx context.Context, c *mssqldb.Client, id *v2.ResourceId) (mssqldb.ServerPrincipalClass, string, error) {
+	switch id.ResourceType {
+	case resourceTypeUser.Id:
+		user, err := c.GetUserPrincipal(ctx, id.Resource)
+		if err != nil {
+			return "", "", err
+		}
+		return mssqldb.ServerPrincipalClassLogin, user.Name, nil
+
+	case resourceTypeGroup.Id:
+		group, err := c.GetGroupPrincipal(ctx, id.Resource)
+		if err != nil {
+			return "", "", err
+		}
+		return mssqldb.ServerPrincipalClassLogin, group.Name, nil
+
+	case resourceTypeServerRole.Id:
+		role, err := c.GetServerRole(ctx, id.Resource)
+		if err != nil {
+			return "", "", err
+		}
+		return mssqldb.ServerPrincipalClassServerRole, role.Name, nil
+
+	default:
+		return "", "", fmt.Errorf("resource type %s is not a server principal", id.ResourceType)
+	}
+}
+
+// serverPrincipalName returns the name of the login, group or user-defined server role that server permissions are provisioned to.
+func serverPrincipalName(ctx context.Context, c *mssqldb.Client, principalID *v2.ResourceId) (string, error) {
+	switch principalID.ResourceType {
+	case resourceTypeUser.Id:
+		user, err := c.GetUserPrincipal(ctx, principalID.Resource)
+		if err != nil {
+			return "", err
+		}
+		return user.Name, nil
+
+	case resourceTypeGroup.Id:
+		group, err := c.GetGroupPrincipal(ctx, principalID.Resource)
+		if err != nil {
+			return "", err
+		}
+		return group.Name, nil
+
+	case resourceTypeServerRole.Id:
+		role, err := c.GetServerRole(ctx, principalID.Resource)
+		if err != nil {
+			return "", err
+		}
+		// Permissions of fixed server roles cannot be changed.
+		if role.IsFixedRole {
+			return "", fmt.Errorf("cannot change permissions of fixed server role %s", role.Name)
+		}
+		return role.Name, nil
+
+	default:
+		return "", fmt.Errorf("resource type %s is not supported for server permissions", principalID.ResourceType)
+	}
+}
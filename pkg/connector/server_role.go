@@ -3,6 +3,7 @@ package connector
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -40,13 +41,7 @@ func (d *serverRolePrincipalSyncer) List(ctx context.Context, parentResourceID *
 
 	var ret []*v2.Resource
 	for _, principalModel := range principals {
-		r, err := sdkResources.NewRoleResource(
-			principalModel.Name,
-			d.ResourceType(ctx),
-			principalModel.ID,
-			nil,
-			sdkResources.WithParentResourceID(parentResourceID),
-		)
+		r, err := d.serverRoleResource(ctx, principalModel, parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -62,12 +57,17 @@ func (d *serverRolePrincipalSyncer) Entitlements(ctx context.Context, resource *
 	ret = append(ret, enTypes.NewAssignmentEntitlement(
 		resource,
 		"member",
-		enTypes.WithGrantableTo(resourceTypeUser),
+		enTypes.WithGrantableTo(resourceTypeUser, resourceTypeGroup, resourceTypeServerRole),
 	))
+	ret = append(ret, ownerEntitlement(resource))
+	ret = append(ret, serverPrincipalPermissionEntitlements(d.client, resource, serverRolePermissions)...)
 
 	return ret, "", nil, nil
 }
 
+// serverRolePermissionsPage is the pagination state for the permissions granted on the server role itself.
+const serverRolePermissionsPage = "server-role-permissions"
+
 type roleGrantPaging struct {
 	PageToken   string          `json:"page_token"`
 	NestedRoles map[string]bool `json:"nested_roles"`
@@ -135,11 +135,42 @@ func (d *serverRolePrincipalSyncer) Grants(ctx context.Context, resource *v2.Res
 	switch b.ResourceTypeID() {
 	case "init":
 		b.Pop()
+		b.Push(pagination.PageState{
+			ResourceTypeID: serverRolePermissionsPage,
+			ResourceID:     resource.Id.Resource,
+		})
 		b.Push(pagination.PageState{
 			ResourceTypeID: resourceTypeServerRole.Id,
 			ResourceID:     resource.Id.Resource,
 		})
 
+		owner, err := d.client.GetServerRoleOwner(ctx, resource.Id.Resource)
+		if err != nil && !errors.Is(err, mssqldb.ErrNoOwner) {
+			return nil, "", nil, err
+		}
+		if owner != nil {
+			ownerGrant, err := serverOwnerGrant(ctx, d.client, resource, owner)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if ownerGrant != nil {
+				ret = append(ret, ownerGrant)
+			}
+		}
+
+	case serverRolePermissionsPage:
+		grants, nextPageToken, err := serverPrincipalPermissionGrants(ctx, d.client, resource, serverRolePermissions, &mssqldb.Pager{Token: b.PageToken(), Size: pToken.Size})
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		err = b.Next(nextPageToken)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		ret = append(ret, grants...)
+
 	case resourceTypeServerRole.Id:
 		principals, nextPageToken, err := d.client.ListServerRolePrincipals(ctx, b.ResourceID(), &mssqldb.Pager{Token: b.PageToken(), Size: pToken.Size})
 		if err != nil {
@@ -173,6 +204,11 @@ func (d *serverRolePrincipalSyncer) Grants(ctx context.Context, resource *v2.Res
 				continue
 			}
 
+			// Members of filtered nested roles are still expanded, only the filtered principal is left out.
+			if !d.client.PrincipalAllowed(principal.Name) {
+				continue
+			}
+
 			principalID, err := sdkResources.NewResourceID(rt, principal.ID)
 			if err != nil {
 				return nil, "", nil, err
@@ -198,11 +234,19 @@ func (d *serverRolePrincipalSyncer) Grants(ctx context.Context, resource *v2.Res
 func (d *serverRolePrincipalSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
 	var err error
 
-	if resource.Id.ResourceType != resourceTypeUser.Id {
-		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+	if strings.HasSuffix(entitlement.Id, ":"+ownerSlug) {
+		return d.grantOwner(ctx, resource, entitlement)
+	}
+
+	if !strings.HasSuffix(entitlement.Id, ":member") {
+		grants, err := grantServerPrincipalPermission(ctx, d.client, resource, entitlement, serverRolePermissions)
+		if err != nil {
+			return nil, nil, err
+		}
+		return grants, nil, nil
 	}
 
-	// database-role:baton_test:6:member
+	// server-role:3:member
 	splitId := strings.Split(entitlement.Id, ":")
 	if len(splitId) < 2 {
 		return nil, nil, fmt.Errorf("unexpected entitlement id: %s", entitlement.Id)
@@ -217,30 +261,49 @@ func (d *serverRolePrincipalSyncer) Grant(ctx context.Context, resource *v2.Reso
 		return nil, nil, err
 	}
 
-	err = d.client.AddUserToServerRole(ctx, role.Name, resource.Id.Resource)
+	member, err := d.memberName(ctx, resource.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resource.Id.ResourceType == resourceTypeServerRole.Id {
+		err = d.checkNestingCycle(ctx, role, resource.Id.Resource, member)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = d.client.AddMemberToServerRole(ctx, role.Name, member)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	grants := []*v2.Grant{
-		grTypes.NewGrant(resource, "member", &v2.ResourceId{
-			Resource:     resource.Id.Resource,
-			ResourceType: resourceTypeUser.Id,
-		}),
+		grTypes.NewGrant(entitlement.Resource, "member", resource.Id),
 	}
 
 	return grants, nil, nil
 }
 
 func (d *serverRolePrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
-	userId := grant.Principal.Id.Resource
+	if strings.HasSuffix(grant.Entitlement.Id, ":"+ownerSlug) {
+		return nil, errRevokeOwner
+	}
+
+	if !strings.HasSuffix(grant.Entitlement.Id, ":member") {
+		err := revokeServerPrincipalPermission(ctx, d.client, grant, serverRolePermissions)
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
 
-	user, err := d.client.GetUserPrincipal(ctx, userId)
+	member, err := d.memberName(ctx, grant.Principal.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	// database-role:baton_test:6:member
+	// server-role:3:member
 	splitId := strings.Split(grant.Entitlement.Id, ":")
 	if len(splitId) < 2 {
 		return nil, fmt.Errorf("unexpected entitlement id: %s", grant.Entitlement.Id)
@@ -253,7 +316,7 @@ func (d *serverRolePrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant)
 		return nil, err
 	}
 
-	err = d.client.RevokeUserToServerRole(ctx, role.Name, user.Name)
+	err = d.client.RevokeUserToServerRole(ctx, role.Name, member)
 	if err != nil {
 		return nil, err
 	}
@@ -261,6 +324,154 @@ func (d *serverRolePrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant)
 	return nil, err
 }
 
+// memberName returns the name of the login or server role to add to or drop from a server role.
+func (d *serverRolePrincipalSyncer) memberName(ctx context.Context, principal *v2.ResourceId) (string, error) {
+	switch principal.ResourceType {
+	case resourceTypeUser.Id:
+		user, err := d.client.GetUserPrincipal(ctx, principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		return user.Name, nil
+
+	case resourceTypeGroup.Id:
+		group, err := d.client.GetGroupPrincipal(ctx, principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		return group.Name, nil
+
+	case resourceTypeServerRole.Id:
+		role, err := d.client.GetServerRole(ctx, principal.Resource)
+		if err != nil {
+			return "", err
+		}
+		return role.Name, nil
+
+	default:
+		return "", fmt.Errorf("resource type %s is not supported as a server role member", principal.ResourceType)
+	}
+}
+
+// checkNestingCycle returns an error if adding the member role to the role would make the roles members of each other.
+func (d *serverRolePrincipalSyncer) checkNestingCycle(ctx context.Context, role *mssqldb.RoleModel, memberRoleID string, memberRoleName string) error {
+	if strconv.FormatInt(role.ID, 10) == memberRoleID {
+		return fmt.Errorf("cannot add server role %s to itself", role.Name)
+	}
+
+	isMember, err := d.client.IsServerRoleMember(ctx, memberRoleID, strconv.FormatInt(role.ID, 10))
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return fmt.Errorf("cannot add server role %s to %s: %s is already a member of %s", memberRoleName, role.Name, role.Name, memberRoleName)
+	}
+
+	return nil
+}
+
+// grantOwner transfers ownership of the server role to the user.
+func (d *serverRolePrincipalSyncer) grantOwner(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if resource.Id.ResourceType != resourceTypeUser.Id {
+		return nil, nil, fmt.Errorf("resource type %s is not supported for granting", resource.Id.ResourceType)
+	}
+
+	role, err := d.client.GetServerRole(ctx, entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = d.client.ChangeServerRoleOwner(ctx, role.Name, user.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []*v2.Grant{grTypes.NewGrant(entitlement.Resource, ownerSlug, resource.Id)}, nil, nil
+}
+
+func (d *serverRolePrincipalSyncer) serverRoleResource(ctx context.Context, role *mssqldb.RoleModel, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	return sdkResources.NewRoleResource(
+		role.Name,
+		d.ResourceType(ctx),
+		role.ID,
+		nil,
+		sdkResources.WithParentResourceID(parentResourceID),
+	)
+}
+
+// Create creates a user-defined server role. The owner can be set with the 'owner' field of the role profile.
+func (d *serverRolePrincipalSyncer) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	parentResourceID := resource.GetParentResourceId()
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeServer.Id {
+		return nil, nil, fmt.Errorf("server roles must have a server as the parent resource")
+	}
+
+	name := resource.GetDisplayName()
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing server role name")
+	}
+	if !d.client.PrincipalAllowed(name) {
+		return nil, nil, fmt.Errorf("%w: %s", mssqldb.ErrPrincipalFiltered, name)
+	}
+
+	owner, err := roleOwnerFromResource(resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = d.client.CreateServerRole(ctx, name, owner)
+	if err != nil {
+		l.Error("failed to create server role", zap.Error(err), zap.String("name", name))
+		return nil, nil, fmt.Errorf("failed to create server role: %w", err)
+	}
+
+	role, err := d.client.GetServerRoleByName(ctx, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get server role: %w", err)
+	}
+
+	r, err := d.serverRoleResource(ctx, role, parentResourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r, nil, nil
+}
+
+// Delete drops a user-defined server role. Fixed roles and roles that still have members are not dropped.
+func (d *serverRolePrincipalSyncer) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	role, err := d.client.GetServerRole(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.IsFixedRole || role.Name == mssqldb.PublicRoleName {
+		return nil, fmt.Errorf("cannot delete fixed server role %s", role.Name)
+	}
+
+	members, err := d.client.CountServerRoleMembers(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+	if members > 0 {
+		return nil, fmt.Errorf("cannot delete server role %s: the role has %d members", role.Name, members)
+	}
+
+	err = d.client.DropServerRole(ctx, role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
 func newServerRolePrincipalSyncer(ctx context.Context, c *mssqldb.Client) *serverRolePrincipalSyncer {
 	return &serverRolePrincipalSyncer{
 		resourceType: resourceTypeServerRole,
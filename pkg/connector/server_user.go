@@ -3,21 +3,26 @@ package connector
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/mail"
+	"strconv"
 	"strings"
+	"time"
 
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	_ "github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
-	enTypes "github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	grTypes "github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-sql-server/pkg/mssqldb"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 var _ connectorbuilder.ResourceDeleter = (*userPrincipalSyncer)(nil)
@@ -26,6 +31,11 @@ var _ connectorbuilder.ResourceDeleter = (*userPrincipalSyncer)(nil)
 type userPrincipalSyncer struct {
 	resourceType *v2.ResourceType
 	client       *mssqldb.Client
+	// passwordMustChange makes SQL logins change a rotated password at their next login.
+	passwordMustChange bool
+	deleteOptions      *loginDeleteOptions
+	lastLogins         *lastLogins
+	accountTypes       *accountTypeClassifier
 }
 
 var loginPermissions = map[string]string{
@@ -49,14 +59,23 @@ func (d *userPrincipalSyncer) List(ctx context.Context, parentResourceID *v2.Res
 		return nil, "", nil, err
 	}
 
+	if pToken.Token == "" {
+		d.lastLogins.refresh(ctx, d.client)
+	}
+
 	var ret []*v2.Resource
 	for _, principalModel := range principals {
-		status := v2.UserTrait_Status_STATUS_ENABLED
-		if principalModel.IsDisabled {
-			status = v2.UserTrait_Status_STATUS_DISABLED
+		userOpts := []resource.UserTraitOption{
+			loginStatus(principalModel),
+			resource.WithUserProfile(loginProfile(principalModel)),
+			resource.WithAccountType(d.accountTypes.classify(principalModel)),
+		}
+		if !principalModel.CreateDate.IsZero() {
+			userOpts = append(userOpts, resource.WithCreatedAt(principalModel.CreateDate))
+		}
+		if lastLogin, ok := d.lastLogins.get(principalModel.Name); ok {
+			userOpts = append(userOpts, resource.WithLastLogin(lastLogin))
 		}
-
-		userOpts := []resource.UserTraitOption{resource.WithStatus(status)}
 
 		if _, err = mail.ParseAddress(principalModel.Name); err == nil {
 			userOpts = append(userOpts, resource.WithEmail(principalModel.Name, true))
@@ -79,17 +98,34 @@ func (d *userPrincipalSyncer) List(ctx context.Context, parentResourceID *v2.Res
 }
 
 func (d *userPrincipalSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	var ret []*v2.Entitlement
+	return serverPrincipalPermissionEntitlements(d.client, resource, loginPermissions), "", nil, nil
+}
 
-	for key, name := range loginPermissions {
-		ret = append(ret, enTypes.NewPermissionEntitlement(resource, key, enTypes.WithDisplayName(name)))
+func (d *userPrincipalSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	ret, nextPageToken, err := serverPrincipalPermissionGrants(ctx, d.client, resource, loginPermissions, &mssqldb.Pager{Token: pToken.Token, Size: pToken.Size})
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	return ret, "", nil, nil
+	return ret, nextPageToken, nil, nil
 }
 
-func (d *userPrincipalSyncer) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	return nil, "", nil, nil
+func (d *userPrincipalSyncer) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	grants, err := grantServerPrincipalPermission(ctx, d.client, resource, entitlement, loginPermissions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return grants, nil, nil
+}
+
+func (d *userPrincipalSyncer) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	err := revokeServerPrincipalPermission(ctx, d.client, grant, loginPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
 }
 
 // CreateAccount creates a SQL Server login based on the specified login type.
@@ -122,13 +158,35 @@ func (d *userPrincipalSyncer) CreateAccount(
 		domain = domainVal.GetStringValue()
 	}
 
-	formattedUsername, password, err := formatUserLogin(ctx, loginType, username, domain)
+	formattedUsername, err := formatUserLogin(ctx, loginType, username, domain)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !d.client.PrincipalAllowed(formattedUsername) {
+		return nil, nil, nil, fmt.Errorf("%w: %s", mssqldb.ErrPrincipalFiltered, formattedUsername)
+	}
+
+	// SQL logins cannot be created without a password, so one is generated unless a random password of a given length is requested.
+	if loginType == mssqldb.LoginTypeSQL {
+		length := defaultPasswordLength
+		if credentialOptions.GetRandomPassword() != nil {
+			length, err = randomPasswordLength(credentialOptions)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		password = generateStrongPassword(length)
+		l.Debug("generated random password for SQL Server authentication", zap.Int("length", length))
+	}
+
+	// The databases and roles are checked before the login is created, so a typo does not leave a login behind.
+	access, err := d.databaseAccessFromProfile(ctx, accountInfo.Profile)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	// Create the login
-	err = d.client.CreateLogin(ctx, loginType, formattedUsername, password)
+	err = d.client.CreateLogin(ctx, loginType, formattedUsername, password, loginOptionsFromProfile(accountInfo.Profile))
 	if err != nil {
 		l.Error("Failed to create login", zap.Error(err), zap.String("loginType", string(loginType)))
 		return nil, nil, nil, fmt.Errorf("failed to create login: %w", err)
@@ -136,7 +194,21 @@ func (d *userPrincipalSyncer) CreateAccount(
 
 	uid, err := d.client.GetUserPrincipalByName(ctx, formattedUsername)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get user: %w", err)
+		if dropErr := d.client.DeleteUserFromServer(ctx, formattedUsername); dropErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get user: %w, and the login could not be dropped: %w", err, dropErr)
+		}
+		return nil, nil, nil, fmt.Errorf("failed to get user, login %s was dropped: %w", formattedUsername, err)
+	}
+
+	var annos annotations.Annotations
+	grants, err := d.provisionDatabaseAccess(ctx, uid, access, accountInfo.Profile.GetFields()["default_schema"].GetStringValue())
+	if err != nil {
+		l.Error("failed to provision database access", zap.Error(err), zap.String("login", formattedUsername))
+		return nil, nil, nil, err
+	}
+	// The response has no field for grants, they are reported as annotations.
+	for _, g := range grants {
+		annos.Append(g)
 	}
 
 	// Create a resource for the newly created login
@@ -191,16 +263,177 @@ func (d *userPrincipalSyncer) CreateAccount(
 		}
 	}
 
-	return successResult, plaintextData, nil, nil
+	return successResult, plaintextData, annos, nil
+}
+
+// databaseAccess is a database a new login is given access to, with the database roles its user is added to.
+type databaseAccess struct {
+	db    *mssqldb.DbModel
+	roles []*mssqldb.RoleModel
 }
 
-func formatUserLogin(ctx context.Context, loginType mssqldb.LoginType, username string, domain string) (string, string, error) {
-	var formattedUsername, password string
+// databaseAccessFromProfile looks up the databases listed in the account profile and the requested database roles in
+// each of them, so that no login is created when one of them does not exist.
+func (d *userPrincipalSyncer) databaseAccessFromProfile(ctx context.Context, profile *structpb.Struct) ([]*databaseAccess, error) {
+	fields := profile.GetFields()
+	databases := profileStringList(fields["databases"])
+	roles := profileStringList(fields["database_roles"])
+
+	var ret []*databaseAccess
+	for _, dbName := range databases {
+		db, err := d.client.GetDatabaseByName(ctx, dbName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get database %s: %w", dbName, err)
+		}
+
+		access := &databaseAccess{db: db}
+		for _, roleName := range roles {
+			role, err := d.client.GetDatabaseRoleByName(ctx, db.Name, roleName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get role %s in database %s: %w", roleName, db.Name, err)
+			}
+			access.roles = append(access.roles, role)
+		}
+		ret = append(ret, access)
+	}
+
+	return ret, nil
+}
+
+// databaseUserRef names a database user, or the membership of a database user in a role when role is set.
+type databaseUserRef struct {
+	db   string
+	user string
+	role string
+}
+
+// accountRollback records the database users and role memberships created for a new login, so they can be undone.
+type accountRollback struct {
+	users       []databaseUserRef
+	memberships []databaseUserRef
+}
+
+// provisionDatabaseAccess creates the database users of a new login in the given databases, with the default schema,
+// and adds them to the database roles of each database.
+// It returns the grants the new login received: CONNECT on each database and the memberships of the roles.
+// When a step fails, the memberships and users created so far and the login itself are dropped, so the account
+// creation can be retried.
+func (d *userPrincipalSyncer) provisionDatabaseAccess(
+	ctx context.Context,
+	user *mssqldb.UserModel,
+	access []*databaseAccess,
+	defaultSchema string,
+) ([]*v2.Grant, error) {
+	var rollback accountRollback
+
+	grants, err := d.grantDatabaseAccess(ctx, user, access, defaultSchema, &rollback)
+	if err != nil {
+		rollbackErr := d.rollbackAccount(ctx, user.Name, &rollback)
+		if rollbackErr != nil {
+			return nil, fmt.Errorf("login %s was created but database access could not be provisioned: %w, and the login could not be dropped: %w", user.Name, err, rollbackErr)
+		}
+		return nil, fmt.Errorf("database access could not be provisioned, login %s was dropped: %w", user.Name, err)
+	}
+
+	return grants, nil
+}
+
+func (d *userPrincipalSyncer) grantDatabaseAccess(
+	ctx context.Context,
+	user *mssqldb.UserModel,
+	access []*databaseAccess,
+	defaultSchema string,
+	rollback *accountRollback,
+) ([]*v2.Grant, error) {
+	principalID := &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: user.ID}
+
+	var ret []*v2.Grant
+	for _, a := range access {
+		// An orphaned user with the name of the login is remapped rather than created, and is left in place on rollback.
+		existing, err := d.client.GetDatabaseUserByName(ctx, a.db.Name, user.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		dbUser, err := ensureDatabaseUserForLogin(ctx, d.client, a.db.Name, user.ID, user.Name)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			rollback.users = append(rollback.users, databaseUserRef{db: a.db.Name, user: dbUser})
+		}
+
+		if defaultSchema != "" {
+			err = d.client.SetDatabaseUserDefaultSchema(ctx, a.db.Name, dbUser, defaultSchema)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		dbResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeDatabase.Id, Resource: strconv.FormatInt(a.db.ID, 10)}}
+		ret = append(ret, grTypes.NewGrant(dbResource, "CO", principalID))
+
+		for _, role := range a.roles {
+			err = d.client.AddUserToDatabaseRole(ctx, role.Name, a.db.Name, dbUser)
+			if err != nil {
+				return nil, err
+			}
+			rollback.memberships = append(rollback.memberships, databaseUserRef{db: a.db.Name, user: dbUser, role: role.Name})
+
+			roleResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeDatabaseRole.Id, Resource: fmt.Sprintf("%s:%d", a.db.Name, role.ID)}}
+			ret = append(ret, grTypes.NewGrant(roleResource, "member", principalID))
+		}
+	}
+
+	return ret, nil
+}
+
+// rollbackAccount drops the role memberships and database users recorded for a new login, and then the login.
+func (d *userPrincipalSyncer) rollbackAccount(ctx context.Context, login string, rollback *accountRollback) error {
+	l := ctxzap.Extract(ctx)
+
+	var errs []error
+	for _, m := range rollback.memberships {
+		err := d.client.RevokeUserToDatabaseRole(ctx, m.role, m.db, m.user)
+		if err != nil {
+			l.Error("failed to roll back role membership", zap.Error(err), zap.String("db", m.db), zap.String("role", m.role), zap.String("user", m.user))
+			errs = append(errs, err)
+		}
+	}
+
+	for _, u := range rollback.users {
+		err := d.client.DropDatabaseUser(ctx, u.db, u.user)
+		if err != nil {
+			l.Error("failed to roll back database user", zap.Error(err), zap.String("db", u.db), zap.String("user", u.user))
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		// The login is kept while one of its users is left, as the user would be orphaned.
+		return errors.Join(errs...)
+	}
+
+	return d.client.DeleteUserFromServer(ctx, login)
+}
+
+// profileStringList returns the strings of a list value of an account profile.
+func profileStringList(v *structpb.Value) []string {
+	var ret []string
+	for _, item := range v.GetListValue().GetValues() {
+		if s := item.GetStringValue(); s != "" {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+func formatUserLogin(ctx context.Context, loginType mssqldb.LoginType, username string, domain string) (string, error) {
+	var formattedUsername string
 	l := ctxzap.Extract(ctx)
 
 	// Check for invalid characters to prevent SQL injection
 	if (domain != "" && strings.ContainsAny(domain, "[]\"';")) || strings.ContainsAny(username, "[]\"';") {
-		return "", "", fmt.Errorf("invalid characters in domain or username")
+		return "", fmt.Errorf("invalid characters in domain or username")
 	}
 
 	switch loginType {
@@ -213,10 +446,7 @@ func formatUserLogin(ctx context.Context, loginType mssqldb.LoginType, username
 			l.Debug("windows login will be created without domain", zap.String("login", formattedUsername))
 		}
 
-	case mssqldb.LoginTypeSQL:
-		// For SQL auth, generate a strong random password
-		password = generateStrongPassword()
-		l.Debug("generated random password for SQL Server authentication")
+	case mssqldb.LoginTypeSQL, mssqldb.LoginTypeCertificate, mssqldb.LoginTypeAsymmetricKey:
 		formattedUsername = username
 
 	case mssqldb.LoginTypeAzureAD, mssqldb.LoginTypeEntraID:
@@ -224,10 +454,39 @@ func formatUserLogin(ctx context.Context, loginType mssqldb.LoginType, username
 		formattedUsername = username
 
 	default:
-		return "", "", fmt.Errorf("unsupported login type: %s", loginType)
+		return "", fmt.Errorf("unsupported login type: %s", loginType)
+	}
+
+	return formattedUsername, nil
+}
+
+// loginOptionsFromProfile reads the optional login settings of the account creation schema from the account profile.
+func loginOptionsFromProfile(profile *structpb.Struct) *mssqldb.LoginOptions {
+	fields := profile.GetFields()
+
+	boolOption := func(name string) *bool {
+		v, ok := fields[name]
+		if !ok {
+			return nil
+		}
+		b, ok := v.GetKind().(*structpb.Value_BoolValue)
+		if !ok {
+			return nil
+		}
+		return &b.BoolValue
 	}
 
-	return formattedUsername, password, nil
+	mustChange := boolOption("must_change")
+
+	return &mssqldb.LoginOptions{
+		DefaultDatabase: fields["default_database"].GetStringValue(),
+		DefaultLanguage: fields["default_language"].GetStringValue(),
+		CheckPolicy:     boolOption("check_policy"),
+		CheckExpiration: boolOption("check_expiration"),
+		MustChange:      mustChange != nil && *mustChange,
+		Certificate:     fields["certificate"].GetStringValue(),
+		AsymmetricKey:   fields["asymmetric_key"].GetStringValue(),
+	}
 }
 
 // CreateAccountCapabilityDetails returns the capability details for account creation.
@@ -243,30 +502,54 @@ func (d *userPrincipalSyncer) CreateAccountCapabilityDetails(
 	}, nil, nil
 }
 
+// Delete drops a login. Depending on the delete options, its sessions are killed, its database users are dropped or
+// re-mapped and the databases, jobs, schemas and roles it owns are transferred to the fallback owner first.
+// What was changed is reported in a struct annotation.
 func (d *userPrincipalSyncer) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
 	user, err := d.client.GetUserPrincipal(ctx, resourceId.GetResource())
 	if err != nil {
 		return nil, err
 	}
 
+	report, err := cleanupLogin(ctx, d.client, user, d.deleteOptions)
+	if err != nil {
+		return nil, err
+	}
+
 	err = d.client.DeleteUserFromServer(ctx, user.Name)
 	if err != nil {
 		return nil, err
 	}
-	return nil, nil
+
+	var annos annotations.Annotations
+	reportAnnotation, err := report.annotation()
+	if err != nil {
+		return nil, err
+	}
+	annos.Append(reportAnnotation)
+
+	return annos, nil
 }
 
+const (
+	// defaultPasswordLength is the length of generated passwords when no length is requested.
+	defaultPasswordLength = 16
+	// minPasswordLength and maxPasswordLength bound the requested length: SQL Server requires 8 characters
+	// for passwords checked by the policy and accepts up to 128.
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
 // generateStrongPassword creates a secure random password for SQL Server.
 // The password meets SQL Server complexity requirements:
 // - At least 8 characters in length
 // - Contains uppercase, lowercase, numbers, and special characters.
-func generateStrongPassword() string {
+func generateStrongPassword(passwordLength int) string {
 	const (
 		uppercaseChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 		lowercaseChars = "abcdefghijklmnopqrstuvwxyz"
 		numberChars    = "0123456789"
 		specialChars   = "!@#$%^&*()-_=+[]{}|;:,.<>?"
-		passwordLength = 16
 	)
 
 	// Ensure at least one character from each category
@@ -303,9 +586,272 @@ func generateStrongPassword() string {
 	return string(password)
 }
 
-func newUserPrincipalSyncer(ctx context.Context, c *mssqldb.Client) *userPrincipalSyncer {
+// randomPasswordLength returns the length of the random password requested by the credential options.
+func randomPasswordLength(credentialOptions *v2.CredentialOptions) (int, error) {
+	randomPassword := credentialOptions.GetRandomPassword()
+	if randomPassword == nil {
+		return 0, fmt.Errorf("only random password credentials are supported")
+	}
+
+	length := randomPassword.GetLength()
+	if length == 0 {
+		return defaultPasswordLength, nil
+	}
+	if length < minPasswordLength || length > maxPasswordLength {
+		return 0, fmt.Errorf("password length must be between %d and %d characters", minPasswordLength, maxPasswordLength)
+	}
+
+	return int(length), nil
+}
+
+// Rotate sets a new random password on a SQL login and returns it. Only SQL logins have a password,
+// Windows and Entra ID logins authenticate outside SQL Server.
+func (d *userPrincipalSyncer) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	length, err := randomPasswordLength(credentialOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := d.client.GetUserPrincipal(ctx, resourceId.GetResource())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.Type != mssqldb.SQLLoginTypeDesc {
+		return nil, nil, fmt.Errorf("cannot rotate the password of %s: only SQL logins have a password", user.Name)
+	}
+
+	password := generateStrongPassword(length)
+	err = d.client.SetLoginPassword(ctx, user.Name, password, d.passwordMustChange)
+	if err != nil {
+		l.Error("failed to rotate login password", zap.Error(err), zap.String("login", user.Name))
+		return nil, nil, fmt.Errorf("failed to rotate login password: %w", err)
+	}
+
+	plaintextData := []*v2.PlaintextData{
+		{
+			Name:        "password",
+			Description: "The new password for SQL Server authentication",
+			Schema:      "text/plain",
+			Bytes:       []byte(password),
+		},
+	}
+
+	return plaintextData, nil, nil
+}
+
+func (d *userPrincipalSyncer) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}, nil, nil
+}
+
+// loginStatus returns the user trait status of a login: disabled logins and logins locked out by the password policy cannot connect.
+func loginStatus(user *mssqldb.UserModel) resource.UserTraitOption {
+	switch {
+	case user.IsDisabled:
+		return resource.WithStatus(v2.UserTrait_Status_STATUS_DISABLED)
+	case user.IsLocked:
+		return resource.WithDetailedStatus(v2.UserTrait_Status_STATUS_DISABLED, "locked")
+	default:
+		return resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED)
+	}
+}
+
+// loginProfile returns the properties of a login used to review it, e.g. to find SQL logins
+// that do not enforce the password policy or whose password was not changed for a long time.
+// Properties that do not apply to the login type are left out.
+func loginProfile(user *mssqldb.UserModel) map[string]interface{} {
+	profile := map[string]interface{}{
+		"login_type":  user.Type,
+		"is_disabled": user.IsDisabled,
+		"is_locked":   user.IsLocked,
+	}
+
+	setTime := func(key string, t time.Time) {
+		// LOGINPROPERTY reports unset times as 1900-01-01.
+		if t.Year() > 1900 {
+			profile[key] = t.UTC().Format(time.RFC3339)
+		}
+	}
+	setTime("create_date", user.CreateDate)
+	setTime("modify_date", user.ModifyDate)
+
+	if user.DefaultDatabase.Valid {
+		profile["default_database"] = user.DefaultDatabase.String
+	}
+	if user.DefaultLanguage.Valid {
+		profile["default_language"] = user.DefaultLanguage.String
+	}
+	if user.IsPolicyChecked.Valid {
+		profile["is_policy_checked"] = user.IsPolicyChecked.Bool
+	}
+	if user.IsExpirationChecked.Valid {
+		profile["is_expiration_checked"] = user.IsExpirationChecked.Bool
+	}
+	if user.IsExpired.Valid {
+		profile["is_expired"] = user.IsExpired.Bool
+	}
+	if user.IsMustChange.Valid {
+		profile["is_must_change"] = user.IsMustChange.Bool
+	}
+	if user.PasswordLastSetTime.Valid {
+		setTime("password_last_set_time", user.PasswordLastSetTime.Time)
+	}
+	if user.DaysUntilExpiration.Valid {
+		profile["days_until_expiration"] = user.DaysUntilExpiration.Int64
+	}
+	if user.BadPasswordCount.Valid {
+		profile["bad_password_count"] = user.BadPasswordCount.Int64
+	}
+	if user.BadPasswordTime.Valid {
+		setTime("bad_password_time", user.BadPasswordTime.Time)
+	}
+	if user.LockoutTime.Valid {
+		setTime("lockout_time", user.LockoutTime.Time)
+	}
+
+	return profile
+}
+
+const (
+	enableLoginActionName  = "enable_login"
+	disableLoginActionName = "disable_login"
+	unlockLoginActionName  = "unlock_login"
+	renameLoginActionName  = "rename_login"
+)
+
+// registerLoginActions registers the actions that change the status or the name of a login.
+// Disabling a login suspends access without dropping it, so its users and permissions are kept.
+func registerLoginActions(m *actionManager, c *mssqldb.Client) {
+	loginArg := stringActionField("login_id", "Login", "The ID of the login, its server principal ID.", true)
+
+	m.register(&v2.BatonActionSchema{
+		Name:        enableLoginActionName,
+		DisplayName: "Enable Login",
+		Description: "Enables a disabled login.",
+		Arguments:   []*config.Field{loginArg},
+		ReturnTypes: actionSuccessReturnTypes,
+	}, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+		user, err := loginArgs(ctx, c, args)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.SetLoginDisabled(ctx, user.Name, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return actionSuccess(), nil
+	})
+
+	m.register(&v2.BatonActionSchema{
+		Name:        disableLoginActionName,
+		DisplayName: "Disable Login",
+		Description: "Disables a login, keeping its users, permissions and role memberships.",
+		Arguments:   []*config.Field{loginArg},
+		ReturnTypes: actionSuccessReturnTypes,
+	}, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+		user, err := loginArgs(ctx, c, args)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.SetLoginDisabled(ctx, user.Name, true)
+		if err != nil {
+			return nil, err
+		}
+
+		return actionSuccess(), nil
+	})
+
+	m.register(&v2.BatonActionSchema{
+		Name:        unlockLoginActionName,
+		DisplayName: "Unlock Login",
+		Description: "Unlocks a SQL login locked out by the password policy, without changing its password.",
+		Arguments:   []*config.Field{loginArg},
+		ReturnTypes: actionSuccessReturnTypes,
+	}, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+		user, err := loginArgs(ctx, c, args)
+		if err != nil {
+			return nil, err
+		}
+
+		if !user.IsLocked {
+			return nil, fmt.Errorf("login %s is not locked", user.Name)
+		}
+
+		err = c.UnlockLogin(ctx, user.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		return actionSuccess(), nil
+	})
+
+	m.register(&v2.BatonActionSchema{
+		Name:        renameLoginActionName,
+		DisplayName: "Rename Login",
+		Description: "Renames a login. The database users of the login keep their names.",
+		Arguments: []*config.Field{
+			loginArg,
+			stringActionField("new_name", "New Name", "The new name of the login.", true),
+		},
+		ReturnTypes: actionSuccessReturnTypes,
+	}, func(ctx context.Context, args *structpb.Struct) (*structpb.Struct, error) {
+		user, err := loginArgs(ctx, c, args)
+		if err != nil {
+			return nil, err
+		}
+
+		newName, err := actionStringArg(args, "new_name", true)
+		if err != nil {
+			return nil, err
+		}
+
+		err = c.RenameLogin(ctx, user.Name, newName)
+		if err != nil {
+			return nil, err
+		}
+
+		return actionSuccess(), nil
+	})
+}
+
+// loginArgs returns the login a login action was invoked with.
+func loginArgs(ctx context.Context, c *mssqldb.Client, args *structpb.Struct) (*mssqldb.UserModel, error) {
+	loginID, err := actionStringArg(args, "login_id", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetUserPrincipal(ctx, loginID)
+}
+
+func newUserPrincipalSyncer(
+	ctx context.Context,
+	c *mssqldb.Client,
+	passwordMustChange bool,
+	deleteOptions *loginDeleteOptions,
+	lastLogins *lastLogins,
+	accountTypes *accountTypeClassifier,
+) *userPrincipalSyncer {
 	return &userPrincipalSyncer{
-		resourceType: resourceTypeUser,
-		client:       c,
+		resourceType:       resourceTypeUser,
+		client:             c,
+		passwordMustChange: passwordMustChange,
+		deleteOptions:      deleteOptions,
+		lastLogins:         lastLogins,
+		accountTypes:       accountTypes,
 	}
 }
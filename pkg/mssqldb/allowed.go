@@ -66,6 +66,7 @@ var DatabasePermissions = map[string]string{
 	"SPLN": "Showplan",
 	"SUQN": "Subscribe Query Notifications",
 	"TO":   "Take Ownership",
+	"UMSK": "Unmask",
 	"UP":   "Update",
 	"VW":   "View Definition",
 	"VWCK": "View Any Column Encryption Key Definition",
@@ -73,3 +74,70 @@ var DatabasePermissions = map[string]string{
 	"VWCT": "View Change Tracking",
 	"VWDS": "View Database State Database",
 }
+
+// SchemaPermissions are the permissions that can be granted on a schema (class 3 securables).
+var SchemaPermissions = map[string]string{
+	"AL":   "Alter",
+	"CL":   "Control",
+	"CRSO": "Create Sequence",
+	"DL":   "Delete",
+	"EX":   "Execute",
+	"IN":   "Insert",
+	"RF":   "References",
+	"SL":   "Select",
+	"TO":   "Take Ownership",
+	"UP":   "Update",
+	"VW":   "View Definition",
+	"VWCT": "View Change Tracking",
+}
+
+// TablePermissions are the permissions that can be granted on tables and views (class 1 securables).
+var TablePermissions = map[string]string{
+	"AL":   "Alter",
+	"CL":   "Control",
+	"DL":   "Delete",
+	"IN":   "Insert",
+	"RF":   "References",
+	"SL":   "Select",
+	"TO":   "Take Ownership",
+	"UP":   "Update",
+	"VW":   "View Definition",
+	"VWCT": "View Change Tracking",
+}
+
+// RoutinePermissions are the permissions that can be granted on procedures and functions (class 1 securables).
+var RoutinePermissions = map[string]string{
+	"AL": "Alter",
+	"CL": "Control",
+	"EX": "Execute",
+	"RF": "References",
+	"TO": "Take Ownership",
+	"VW": "View Definition",
+}
+
+// ColumnPermissions are the permissions that can be granted on individual table columns.
+// UNMASK can only be granted on masked columns and requires SQL Server 2022 at the column level.
+var ColumnPermissions = map[string]string{
+	"RF":   "References",
+	"SL":   "Select",
+	"UMSK": "Unmask",
+	"UP":   "Update",
+}
+
+// ServerPrincipalPermissions are the permissions that can be granted on logins and server roles.
+var ServerPrincipalPermissions = map[string]string{
+	"AL": "ALTER",
+	"CL": "CONTROL",
+	"IM": "IMPERSONATE",
+	"TO": "TAKE OWNERSHIP",
+	"VW": "VIEW DEFINITION",
+}
+
+// DatabasePrincipalPermissions are the permissions that can be granted on database users, roles and application roles.
+var DatabasePrincipalPermissions = map[string]string{
+	"AL": "ALTER",
+	"CL": "CONTROL",
+	"IM": "IMPERSONATE",
+	"TO": "TAKE OWNERSHIP",
+	"VW": "VIEW DEFINITION",
+}
@@ -0,0 +1,240 @@
+package mssqldb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const ApplicationRoleType = "application-role"
+
+type ApplicationRoleModel struct {
+	ID            int64  `db:"principal_id"`
+	Name          string `db:"name"`
+	DefaultSchema string `db:"default_schema_name"`
+	CreateDate    string `db:"create_date"`
+}
+
+func (c *Client) ListApplicationRoles(ctx context.Context, dbName string, pager *Pager) ([]*ApplicationRoleModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing application roles", zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, "", fmt.Errorf("invalid characters in dbName")
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{offset, limit + 1}
+
+	var sb strings.Builder
+	// https://learn.microsoft.com/en-us/sql/relational-databases/security/authentication-access/application-roles
+	_, _ = sb.WriteString(`
+SELECT
+  principal_id,
+  name,
+  ISNULL(default_schema_name, '') AS default_schema_name,
+  create_date
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals
+WHERE type = 'A'
+ORDER BY
+  principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
+`)
+	l.Debug("ListApplicationRoles",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*ApplicationRoleModel
+	for rows.Next() {
+		var appRoleModel ApplicationRoleModel
+		err = rows.StructScan(&appRoleModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &appRoleModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+func (c *Client) GetApplicationRole(ctx context.Context, dbName string, id string) (*ApplicationRoleModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting application role", zap.String("id", id), zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  principal_id,
+  name,
+  ISNULL(default_schema_name, '') AS default_schema_name,
+  create_date
+FROM [%s].sys.database_principals
+WHERE type = 'A' AND principal_id = @p1
+`, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, query, id)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var appRoleModel ApplicationRoleModel
+	err = row.StructScan(&appRoleModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appRoleModel, nil
+}
+
+func (c *Client) GetApplicationRoleByName(ctx context.Context, dbName string, name string) (*ApplicationRoleModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting application role by name", zap.String("name", name), zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  principal_id,
+  name,
+  ISNULL(default_schema_name, '') AS default_schema_name,
+  create_date
+FROM [%s].sys.database_principals
+WHERE type = 'A' AND name = @p1
+`, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, query, name)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var appRoleModel ApplicationRoleModel
+	err = row.StructScan(&appRoleModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appRoleModel, nil
+}
+
+func (c *Client) CreateApplicationRole(ctx context.Context, db, name, password string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("creating application role", zap.String("db", db), zap.String("name", name))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(name, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName or name")
+	}
+
+	if password == "" || strings.Contains(password, "'") {
+		return fmt.Errorf("invalid application role password")
+	}
+
+	command := fmt.Sprintf("USE [%s]; CREATE APPLICATION ROLE [%s] WITH PASSWORD = '%s';", db, name, password)
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) SetApplicationRolePassword(ctx context.Context, db, name, password string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("setting application role password", zap.String("db", db), zap.String("name", name))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(name, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName or name")
+	}
+
+	if password == "" || strings.Contains(password, "'") {
+		return fmt.Errorf("invalid application role password")
+	}
+
+	command := fmt.Sprintf("USE [%s]; ALTER APPLICATION ROLE [%s] WITH PASSWORD = '%s';", db, name, password)
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DropApplicationRole(ctx context.Context, db, name string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("dropping application role", zap.String("db", db), zap.String("name", name))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(name, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName or name")
+	}
+
+	command := fmt.Sprintf("USE [%s]; DROP APPLICATION ROLE [%s];", db, name)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
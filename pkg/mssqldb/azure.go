@@ -0,0 +1,106 @@
+package mssqldb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// WithAzureSQLDatabase connects to each database separately, for Azure SQL Database logical servers which do not
+// support cross-database queries or switching databases with USE. The DSN of the client should connect to master.
+func WithAzureSQLDatabase() Option {
+	return func(c *Client) {
+		c.azureSQLDatabase = true
+	}
+}
+
+// IsAzureSQLDatabase reports whether the client connects to an Azure SQL Database logical server.
+func (c *Client) IsAzureSQLDatabase() bool {
+	return c.azureSQLDatabase
+}
+
+// databaseConn returns the connection per-database queries and commands run on.
+// On Azure SQL Database this is a connection to the database itself, opened on first use and kept for later queries.
+// Three-part names and USE statements that refer to the database of the connection are allowed there,
+// so the queries are the same in both modes.
+func (c *Client) databaseConn(ctx context.Context, dbName string) (*sqlx.DB, error) {
+	if !c.azureSQLDatabase {
+		return c.db, nil
+	}
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	c.databaseConnsMu.Lock()
+	defer c.databaseConnsMu.Unlock()
+
+	if conn, ok := c.databaseConns[dbName]; ok {
+		return conn, nil
+	}
+
+	l := ctxzap.Extract(ctx)
+	l.Debug("connecting to database", zap.String("dbName", dbName))
+
+	dsn, err := databaseDSN(c.dsn, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connect(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database %s: %w", dbName, err)
+	}
+
+	if c.databaseConns == nil {
+		c.databaseConns = make(map[string]*sqlx.DB)
+	}
+	c.databaseConns[dbName] = conn
+
+	return conn, nil
+}
+
+// databaseDSN returns the DSN with its database replaced by dbName.
+// The URL, ADO and ODBC formats accepted by the driver are supported.
+func databaseDSN(dsn string, dbName string) (string, error) {
+	// The DSN holds credentials, so it is left out of the errors.
+	if strings.HasPrefix(dsn, "sqlserver://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("invalid dsn: unable to parse the connection url")
+		}
+		q := u.Query()
+		q.Set("database", dbName)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+
+	if odbc, ok := strings.CutPrefix(dsn, "odbc:"); ok {
+		return "odbc:" + replaceDSNParam(odbc, "database={"+strings.ReplaceAll(dbName, "}", "}}")+"}"), nil
+	}
+
+	return replaceDSNParam(dsn, "database="+dbName), nil
+}
+
+// replaceDSNParam drops the database parameters of a semicolon-separated DSN and appends param.
+func replaceDSNParam(dsn string, param string) string {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSpace(dsn), ";"), ";")
+
+	ret := make([]string, 0, len(parts)+1)
+	for _, p := range parts {
+		key, _, _ := strings.Cut(p, "=")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "database", "initial catalog":
+			continue
+		}
+		ret = append(ret, p)
+	}
+	ret = append(ret, param)
+
+	return strings.Join(ret, ";")
+}
@@ -2,6 +2,7 @@ package mssqldb
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -10,7 +11,31 @@ import (
 
 type Client struct {
 	db                       *sqlx.DB
+	dsn                      string
 	skipUnavailableDatabases bool
+	principalFilter          *PrincipalFilter
+	databaseFilter           *DatabaseFilter
+	azureSQLDatabase         bool
+
+	databaseConnsMu sync.Mutex
+	databaseConns   map[string]*sqlx.DB
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithDatabaseFilter restricts the databases that are listed and provisioned.
+func WithDatabaseFilter(f *DatabaseFilter) Option {
+	return func(c *Client) {
+		c.databaseFilter = f
+	}
+}
+
+// WithPrincipalFilter restricts the server principals that are listed and provisioned.
+func WithPrincipalFilter(f *PrincipalFilter) Option {
+	return func(c *Client) {
+		c.principalFilter = f
+	}
 }
 
 // List databases
@@ -21,20 +46,33 @@ type Client struct {
 
 // List users
 
-func New(ctx context.Context, dsn string, skipUnavailableDatabases bool) (*Client, error) {
-	db, err := sqlx.Connect("sqlserver", dsn)
+func New(ctx context.Context, dsn string, skipUnavailableDatabases bool, opts ...Option) (*Client, error) {
+	db, err := connect(ctx, dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetConnMaxLifetime(time.Minute * 1)
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
-
 	c := &Client{
 		db:                       db,
+		dsn:                      dsn,
 		skipUnavailableDatabases: skipUnavailableDatabases,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 
 	return c, nil
 }
+
+func connect(ctx context.Context, dsn string) (*sqlx.DB, error) {
+	db, err := sqlx.ConnectContext(ctx, "sqlserver", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetConnMaxLifetime(time.Minute * 1)
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	return db, nil
+}
@@ -2,6 +2,8 @@ package mssqldb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,20 +14,91 @@ import (
 
 const DatabaseType = "database"
 
+// ErrDatabaseNotFound is returned when a database does not exist or is excluded by the database filter.
+var ErrDatabaseNotFound = errors.New("database not found")
+
 type DbModel struct {
 	ID        int64  `db:"database_id"`
 	Name      string `db:"name"`
 	StateDesc string `db:"state_desc"`
 }
 
+// DatabaseFilter selects the databases that are synced and provisioned.
+// Database snapshots are always excluded.
+type DatabaseFilter struct {
+	// Include and Exclude are glob patterns matched against database names.
+	Include []string
+	Exclude []string
+	// ExcludeSystem excludes master, model, msdb and tempdb.
+	ExcludeSystem bool
+}
+
+// condition returns a SQL condition that selects the databases of sys.databases passing the filter.
+// The pattern values are appended to args and referenced by their ordinal @pN placeholders.
+func (f *DatabaseFilter) condition(args []interface{}) (string, []interface{}) {
+	var sb strings.Builder
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/sys-databases-transact-sql
+	_, _ = sb.WriteString("source_database_id IS NULL")
+
+	if f == nil {
+		return sb.String(), args
+	}
+
+	if f.ExcludeSystem {
+		_, _ = sb.WriteString(" AND name NOT IN ('master', 'model', 'msdb', 'tempdb')")
+	}
+
+	var filter string
+	filter, args = (&NamePatterns{Include: f.Include, Exclude: f.Exclude}).whereClause("name", args)
+	_, _ = sb.WriteString(filter)
+
+	return sb.String(), args
+}
+
 func (c *Client) GetDatabase(ctx context.Context, id int64) (*DbModel, error) {
 	l := ctxzap.Extract(ctx)
 	l.Debug("fetching database", zap.Int64("database_id", id))
 
+	args := []interface{}{id}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`SELECT name, database_id FROM sys.databases WHERE database_id=@p1 AND `)
+
+	var filter string
+	filter, args = c.databaseFilter.condition(args)
+	_, _ = sb.WriteString(filter)
+
+	row := c.db.QueryRowxContext(ctx, sb.String(), args...)
+	if row.Err() != nil {
+		return nil, row.Err()
+	}
+
+	var ret DbModel
+	err := row.StructScan(&ret)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrDatabaseNotFound, id)
+		}
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+func (c *Client) GetDatabaseByName(ctx context.Context, name string) (*DbModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("fetching database by name", zap.String("name", name))
+
+	args := []interface{}{name}
+
 	var sb strings.Builder
-	_, _ = sb.WriteString(`SELECT name, database_id FROM sys.databases WHERE database_id=@p1`)
+	_, _ = sb.WriteString(`SELECT name, database_id FROM sys.databases WHERE name=@p1 AND `)
 
-	row := c.db.QueryRowxContext(ctx, sb.String(), id)
+	var filter string
+	filter, args = c.databaseFilter.condition(args)
+	_, _ = sb.WriteString(filter)
+
+	row := c.db.QueryRowxContext(ctx, sb.String(), args...)
 	if row.Err() != nil {
 		return nil, row.Err()
 	}
@@ -33,6 +106,9 @@ func (c *Client) GetDatabase(ctx context.Context, id int64) (*DbModel, error) {
 	var ret DbModel
 	err := row.StructScan(&ret)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, name)
+		}
 		return nil, err
 	}
 
@@ -51,6 +127,17 @@ func (c *Client) ListDatabases(ctx context.Context, pager *Pager) ([]*DbModel, s
 
 	var sb strings.Builder
 	_, _ = sb.WriteString(`SELECT name, database_id, state_desc FROM sys.databases
+                                      WHERE `)
+
+	// Filtering in the query keeps the pages full, so the page token stays correct.
+	var filter string
+	filter, args = c.databaseFilter.condition(args)
+	_, _ = sb.WriteString(filter)
+	if c.skipUnavailableDatabases {
+		_, _ = sb.WriteString(" AND state_desc = 'ONLINE'")
+	}
+
+	_, _ = sb.WriteString(`
                                       ORDER BY database_id ASC 
                                       OFFSET @p1 ROWS
                                       FETCH NEXT @p2 ROWS ONLY`)
@@ -70,10 +157,6 @@ func (c *Client) ListDatabases(ctx context.Context, pager *Pager) ([]*DbModel, s
 		if err != nil {
 			return nil, "", err
 		}
-		if c.skipUnavailableDatabases && dbModel.StateDesc != "ONLINE" {
-			l.Info("Skipping sync of unavailable database", zap.String("name", dbModel.Name), zap.String("state", dbModel.StateDesc))
-			continue
-		}
 		ret = append(ret, &dbModel)
 	}
 	if rows.Err() != nil {
@@ -90,13 +173,14 @@ func (c *Client) ListDatabases(ctx context.Context, pager *Pager) ([]*DbModel, s
 	return ret, nextPageToken, nil
 }
 
-func (c *Client) GrantPermissionOnDatabase(ctx context.Context, permission, db, user string) error {
+func (c *Client) GrantPermissionOnDatabase(ctx context.Context, permission, db, user string, withGrantOption bool) error {
 	l := ctxzap.Extract(ctx)
 	l.Debug(
 		"granting permission on database",
 		zap.String("permission", permission),
 		zap.String("db", db),
 		zap.String("user", user),
+		zap.Bool("with_grant_option", withGrantOption),
 	)
 
 	fullPermission, ok := DatabasePermissions[strings.ToUpper(permission)]
@@ -109,15 +193,24 @@ func (c *Client) GrantPermissionOnDatabase(ctx context.Context, permission, db,
 	}
 
 	command := fmt.Sprintf(
-		"GRANT %s ON DATABASE::[%s] TO [%s];",
+		"GRANT %s ON DATABASE::[%s] TO [%s]",
 		fullPermission,
 		db,
 		user,
 	)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
 
 	l.Debug("SQL QUERY", zap.String("q", command))
 
-	_, err := c.db.ExecContext(ctx, command, permission, db, user)
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command, permission, db, user)
 	if err != nil {
 		return err
 	}
@@ -125,10 +218,10 @@ func (c *Client) GrantPermissionOnDatabase(ctx context.Context, permission, db,
 	return nil
 }
 
-func (c *Client) RevokePermissionOnDatabase(ctx context.Context, permission, db, user string) error {
+func (c *Client) DenyPermissionOnDatabase(ctx context.Context, permission, db, user string) error {
 	l := ctxzap.Extract(ctx)
 	l.Debug(
-		"revoking permission on database",
+		"denying permission on database",
 		zap.String("permission", permission),
 		zap.String("db", db),
 		zap.String("user", user),
@@ -144,13 +237,214 @@ func (c *Client) RevokePermissionOnDatabase(ctx context.Context, permission, db,
 	}
 
 	command := fmt.Sprintf(
-		"GRANT %s ON DATABASE::[%s] TO [%s];",
+		"USE [%s]; DENY %s TO [%s];",
+		db,
 		fullPermission,
+		user,
+	)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokePermissionOnDatabase removes a granted or denied permission on the database from the user.
+// cascade must be set when the permission was granted WITH GRANT OPTION.
+func (c *Client) RevokePermissionOnDatabase(ctx context.Context, permission, db, user string, cascade bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on database",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("user", user),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := DatabasePermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; REVOKE %s FROM [%s]",
 		db,
+		fullPermission,
 		user,
 	)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DatabasePrincipalClass is the class name used to reference a database user, role or application role in a GRANT statement.
+type DatabasePrincipalClass string
+
+const (
+	DatabasePrincipalClassUser            DatabasePrincipalClass = "USER"
+	DatabasePrincipalClassRole            DatabasePrincipalClass = "ROLE"
+	DatabasePrincipalClassApplicationRole DatabasePrincipalClass = "APPLICATION ROLE"
+)
+
+// GrantPermissionOnDatabasePrincipal grants a permission on a database user or role, e.g. ALTER ON ROLE::[app_readers].
+func (c *Client) GrantPermissionOnDatabasePrincipal(
+	ctx context.Context,
+	permission string,
+	db string,
+	class DatabasePrincipalClass,
+	securable string,
+	user string,
+	withGrantOption bool,
+) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"granting permission on database principal",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("class", string(class)),
+		zap.String("securable", securable),
+		zap.String("user", user),
+		zap.Bool("with_grant_option", withGrantOption),
+	)
+
+	fullPermission, ok := DatabasePrincipalPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, securable or user")
+	}
+
+	command := fmt.Sprintf("USE [%s]; GRANT %s ON %s::[%s] TO [%s]", db, fullPermission, class, securable, user)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DenyPermissionOnDatabasePrincipal(ctx context.Context, permission, db string, class DatabasePrincipalClass, securable, user string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"denying permission on database principal",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("class", string(class)),
+		zap.String("securable", securable),
+		zap.String("user", user),
+	)
+
+	fullPermission, ok := DatabasePrincipalPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, securable or user")
+	}
+
+	command := fmt.Sprintf("USE [%s]; DENY %s ON %s::[%s] TO [%s];", db, fullPermission, class, securable, user)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) RevokePermissionOnDatabasePrincipal(
+	ctx context.Context,
+	permission string,
+	db string,
+	class DatabasePrincipalClass,
+	securable string,
+	user string,
+	cascade bool,
+) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on database principal",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("class", string(class)),
+		zap.String("securable", securable),
+		zap.String("user", user),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := DatabasePrincipalPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, securable or user")
+	}
+
+	command := fmt.Sprintf("USE [%s]; REVOKE %s ON %s::[%s] FROM [%s]", db, fullPermission, class, securable, user)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
 
-	_, err := c.db.ExecContext(ctx, command, fullPermission, db, user)
+	_, err = conn.ExecContext(ctx, command)
 	if err != nil {
 		return err
 	}
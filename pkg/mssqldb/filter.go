@@ -0,0 +1,111 @@
+package mssqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NamePatterns holds glob patterns used to include or exclude catalog objects by name.
+// A '*' matches any sequence of characters and a '?' matches a single character.
+// An empty include list matches every name.
+type NamePatterns struct {
+	Include []string
+	Exclude []string
+}
+
+// whereClause returns a SQL condition (prefixed with AND) that applies the patterns to the given column expression.
+// The pattern values are appended to args and referenced by their ordinal @pN placeholders.
+func (n *NamePatterns) whereClause(column string, args []interface{}) (string, []interface{}) {
+	if n == nil {
+		return "", args
+	}
+
+	var sb strings.Builder
+
+	if len(n.Include) > 0 {
+		_, _ = sb.WriteString(" AND (")
+		for i, pattern := range n.Include {
+			if i > 0 {
+				_, _ = sb.WriteString(" OR ")
+			}
+			args = append(args, globToLike(pattern))
+			_, _ = sb.WriteString(fmt.Sprintf(`%s LIKE @p%d ESCAPE '\'`, column, len(args)))
+		}
+		_, _ = sb.WriteString(")")
+	}
+
+	for _, pattern := range n.Exclude {
+		args = append(args, globToLike(pattern))
+		_, _ = sb.WriteString(fmt.Sprintf(` AND %s NOT LIKE @p%d ESCAPE '\'`, column, len(args)))
+	}
+
+	return sb.String(), args
+}
+
+// Match reports whether a name matches the patterns, the way whereClause does in SQL.
+func (n *NamePatterns) Match(name string) bool {
+	if n == nil {
+		return true
+	}
+
+	if len(n.Include) > 0 {
+		included := false
+		for _, pattern := range n.Include {
+			if MatchGlob(pattern, name) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, pattern := range n.Exclude {
+		if MatchGlob(pattern, name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// globToLike converts a glob pattern into a LIKE pattern that uses '\' as the escape character.
+func globToLike(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			_, _ = sb.WriteRune('%')
+		case '?':
+			_, _ = sb.WriteRune('_')
+		case '%', '_', '[', '\\':
+			_, _ = sb.WriteRune('\\')
+			_, _ = sb.WriteRune(r)
+		default:
+			_, _ = sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// MatchGlob reports whether a name matches a glob pattern. Like the default collation of SQL Server, the match is
+// case-insensitive.
+func MatchGlob(pattern string, name string) bool {
+	var sb strings.Builder
+	_, _ = sb.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			_, _ = sb.WriteString(".*")
+		case '?':
+			_, _ = sb.WriteString(".")
+		default:
+			_, _ = sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	_, _ = sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String()).MatchString(name)
+}
@@ -0,0 +1,91 @@
+package mssqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobToLike(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "Sales.*", want: "Sales.%"},
+		{pattern: "dbo.Audit?", want: "dbo.Audit_"},
+		{pattern: "tmp_[x]%", want: `tmp\_\[x]\%`},
+		{pattern: `NT SERVICE\*`, want: `NT SERVICE\\%`},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, globToLike(test.pattern))
+	}
+}
+
+func TestNamePatternsWhereClause(t *testing.T) {
+	var nilPatterns *NamePatterns
+	clause, args := nilPatterns.whereClause("name", []interface{}{0, 11})
+	require.Empty(t, clause)
+	require.Len(t, args, 2)
+
+	patterns := &NamePatterns{
+		Include: []string{"Sales.*", "HR.*"},
+		Exclude: []string{"*.tmp*"},
+	}
+	clause, args = patterns.whereClause("name", []interface{}{0, 11})
+	require.Equal(t, ` AND (name LIKE @p3 ESCAPE '\' OR name LIKE @p4 ESCAPE '\') AND name NOT LIKE @p5 ESCAPE '\'`, clause)
+	require.Equal(t, []interface{}{0, 11, "Sales.%", "HR.%", "%.tmp%"}, args)
+}
+
+func TestMatchGlob(t *testing.T) {
+	require.True(t, MatchGlob(`svc_*`, "SVC_Reporting"))
+	require.True(t, MatchGlob(`CONTOSO\svc-*`, `contoso\svc-etl`))
+	require.True(t, MatchGlob(`app?`, "app1"))
+	require.False(t, MatchGlob(`app?`, "app12"))
+	require.False(t, MatchGlob(`svc_*`, "my_svc_reporting"))
+	require.False(t, MatchGlob(`a.b`, "axb"))
+}
+
+func TestPrincipalFilter(t *testing.T) {
+	var nilFilter *PrincipalFilter
+	require.True(t, nilFilter.Allows("##MS_PolicyEventProcessingLogin##"))
+	clause, _ := nilFilter.patterns().whereClause("name", nil)
+	require.Empty(t, clause)
+
+	f := &PrincipalFilter{
+		Exclude:       []string{"test_*"},
+		ExcludeSystem: true,
+	}
+	require.True(t, f.Allows("sa"))
+	require.True(t, f.Allows(`CONTOSO\alice`))
+	require.False(t, f.Allows("##MS_PolicyEventProcessingLogin##"))
+	require.False(t, f.Allows(`NT SERVICE\MSSQLSERVER`))
+	require.False(t, f.Allows("sysadmin"))
+	require.False(t, f.Allows("test_login"))
+	require.Equal(t, []string{"test_*"}, f.Exclude)
+
+	clause, args := f.patterns().whereClause("name", []interface{}{0, 11})
+	require.Contains(t, clause, "name NOT LIKE @p3 ESCAPE '\\'")
+	require.Contains(t, args, `NT SERVICE\\%`)
+
+	f = &PrincipalFilter{Include: []string{`CONTOSO\*`}}
+	require.True(t, f.Allows(`contoso\bob`))
+	require.False(t, f.Allows("bob"))
+}
+
+func TestDatabaseFilterCondition(t *testing.T) {
+	var nilFilter *DatabaseFilter
+	condition, args := nilFilter.condition([]interface{}{0, 11})
+	require.Equal(t, "source_database_id IS NULL", condition)
+	require.Len(t, args, 2)
+
+	f := &DatabaseFilter{
+		Include:       []string{"tenant_*"},
+		Exclude:       []string{"*_archive"},
+		ExcludeSystem: true,
+	}
+	condition, args = f.condition([]interface{}{0, 11})
+	require.Equal(t, `source_database_id IS NULL AND name NOT IN ('master', 'model', 'msdb', 'tempdb')`+
+		` AND (name LIKE @p3 ESCAPE '\') AND name NOT LIKE @p4 ESCAPE '\'`, condition)
+	require.Equal(t, []interface{}{0, 11, "tenant\\_%", "%\\_archive"}, args)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 const GroupType = "group"
@@ -42,7 +43,13 @@ WHERE
   (
     type = 'G' 
     OR type = 'X'
-  ) 
+  ) `)
+
+	var filter string
+	filter, args = c.principalFilter.patterns().whereClause("name", args)
+	_, _ = sb.WriteString(filter)
+
+	_, _ = sb.WriteString(`
 ORDER BY 
   principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
 `)
@@ -75,3 +82,42 @@ ORDER BY
 
 	return ret, nextPageToken, nil
 }
+
+func (c *Client) GetGroupPrincipal(ctx context.Context, id string) (*GroupModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting group principal", zap.String("id", id))
+
+	query := `
+SELECT 
+  principal_id, 
+  sid,
+  name, 
+  type_desc 
+FROM 
+  sys.server_principals
+WHERE 
+  (
+    type = 'G' 
+    OR type = 'X'
+  ) 
+  AND principal_id = @p1
+`
+
+	row := c.db.QueryRowxContext(ctx, query, id)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var groupModel GroupModel
+	err := row.StructScan(&groupModel)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.checkPrincipalAllowed(groupModel.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &groupModel, nil
+}
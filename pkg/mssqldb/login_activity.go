@@ -0,0 +1,285 @@
+package mssqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// LastLoginSource is where the last successful login of each login is read from.
+type LastLoginSource string
+
+const (
+	// LastLoginSourceNone does not track logins.
+	LastLoginSourceNone LastLoginSource = "none"
+	// LastLoginSourceSessions only uses the sessions that are currently connected.
+	LastLoginSourceSessions LastLoginSource = "sessions"
+	// LastLoginSourceErrorLog reads the successful logins written to the error log, which requires login auditing
+	// to be set to successful logins.
+	LastLoginSourceErrorLog LastLoginSource = "errorlog"
+	// LastLoginSourceAudit reads the LOGIN SUCCEEDED events of a SQL Server Audit file.
+	LastLoginSourceAudit LastLoginSource = "audit"
+	// LastLoginSourceTable reads a table maintained by the DBA, with login_name and last_login columns.
+	LastLoginSourceTable LastLoginSource = "table"
+)
+
+// Validate checks that the source is known and that the sources that read a file or table have a location.
+func (s LastLoginSource) Validate(location string) error {
+	switch s {
+	case LastLoginSourceNone, LastLoginSourceSessions, LastLoginSourceErrorLog:
+		return nil
+	case LastLoginSourceAudit, LastLoginSourceTable:
+		if location == "" {
+			return fmt.Errorf("the %s last login source requires a location", s)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid last login source %q: must be none, sessions, errorlog, audit or table", s)
+	}
+}
+
+// ListLastLogins returns the time of the last successful login, in UTC, of each login by name.
+// The sessions that are currently connected are used for logins the source has no record of, and for logins that
+// connected after the source was last written to.
+func (c *Client) ListLastLogins(ctx context.Context, source LastLoginSource, location string) (map[string]time.Time, error) {
+	ret := make(map[string]time.Time)
+
+	var err error
+	switch source {
+	case LastLoginSourceNone:
+		return ret, nil
+	case LastLoginSourceSessions:
+	case LastLoginSourceErrorLog:
+		ret, err = c.listLastLoginsFromErrorLog(ctx)
+	case LastLoginSourceAudit:
+		ret, err = c.listLastLoginsFromAuditFile(ctx, location)
+	case LastLoginSourceTable:
+		ret, err = c.listLastLoginsFromTable(ctx, location)
+	default:
+		return nil, fmt.Errorf("invalid last login source %q", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := c.listLastLoginsFromSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mergeLastLogins(ret, sessions)
+
+	return ret, nil
+}
+
+type lastLoginModel struct {
+	Name      string    `db:"login_name"`
+	LastLogin time.Time `db:"last_login"`
+}
+
+func (c *Client) selectLastLogins(ctx context.Context, query string, args ...interface{}) (map[string]time.Time, error) {
+	var rows []*lastLoginModel
+	err := c.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		ret[r.Name] = r.LastLogin
+	}
+	return ret, nil
+}
+
+// listLastLoginsFromSessions returns the last login of the logins that have a user session open.
+// Session times are in the local time of the server and are converted to UTC.
+func (c *Client) listLastLoginsFromSessions(ctx context.Context) (map[string]time.Time, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing last logins from sessions")
+
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-dynamic-management-views/sys-dm-exec-sessions-transact-sql
+	query := `
+SELECT
+  login_name,
+  DATEADD(minute, DATEDIFF(minute, GETDATE(), GETUTCDATE()), MAX(login_time)) AS last_login
+FROM sys.dm_exec_sessions
+WHERE is_user_process = 1
+GROUP BY login_name
+`
+
+	return c.selectLastLogins(ctx, query)
+}
+
+// listLastLoginsFromAuditFile returns the last LOGIN SUCCEEDED (LGIS) event of each login in the audit files
+// matching the path, e.g. 'D:\Audit\*.sqlaudit'. Audit event times are in UTC.
+func (c *Client) listLastLoginsFromAuditFile(ctx context.Context, path string) (map[string]time.Time, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing last logins from audit file", zap.String("path", path))
+
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-functions/sys-fn-get-audit-file-transact-sql
+	query := `
+SELECT
+  server_principal_name AS login_name,
+  CAST(MAX(event_time) AS datetime2) AS last_login
+FROM sys.fn_get_audit_file(@p1, DEFAULT, DEFAULT)
+WHERE action_id = 'LGIS'
+GROUP BY server_principal_name
+`
+
+	return c.selectLastLogins(ctx, query, path)
+}
+
+// listLastLoginsFromTable returns the last logins recorded in a table, e.g. one filled by a logon trigger.
+// The table must have login_name and last_login columns, last_login being in UTC.
+func (c *Client) listLastLoginsFromTable(ctx context.Context, table string) (map[string]time.Time, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing last logins from table", zap.String("table", table))
+
+	tableName, err := quoteObjectName(table)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  login_name,
+  MAX(last_login) AS last_login
+FROM %s
+GROUP BY login_name
+`, tableName)
+
+	return c.selectLastLogins(ctx, query)
+}
+
+// quoteObjectName quotes each part of a (possibly database and schema qualified) object name, e.g. 'audit.dbo.logins'.
+func quoteObjectName(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("invalid object name: %s", name)
+	}
+
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" || strings.ContainsAny(part, "[]\"';") {
+			return "", fmt.Errorf("invalid object name: %s", name)
+		}
+		quoted = append(quoted, fmt.Sprintf("[%s]", part))
+	}
+
+	return strings.Join(quoted, "."), nil
+}
+
+// errorLogLoginPattern matches the message written to the error log when login auditing records successful logins.
+var errorLogLoginPattern = regexp.MustCompile(`^Login succeeded for user '([^']+)'`)
+
+// parseErrorLogLogin returns the login of a 'Login succeeded' error log message.
+func parseErrorLogLogin(text string) (string, bool) {
+	m := errorLogLoginPattern.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// listLastLoginsFromErrorLog returns the last successful login of each login found in the current and archived
+// error logs. Error log times are in the local time of the server and are converted to UTC.
+func (c *Client) listLastLoginsFromErrorLog(ctx context.Context) (map[string]time.Time, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing last logins from error log")
+
+	var utcOffset int
+	err := c.db.QueryRowxContext(ctx, "SELECT DATEDIFF(minute, GETDATE(), GETUTCDATE())").Scan(&utcOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	archives, err := c.listErrorLogArchives(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]time.Time)
+	for _, archive := range archives {
+		logins, err := c.readErrorLogLogins(ctx, archive)
+		if err != nil {
+			return nil, err
+		}
+		mergeLastLogins(ret, logins)
+	}
+
+	for name, t := range ret {
+		ret[name] = t.Add(time.Duration(utcOffset) * time.Minute)
+	}
+
+	return ret, nil
+}
+
+// listErrorLogArchives returns the numbers of the error logs, 0 being the current one.
+func (c *Client) listErrorLogArchives(ctx context.Context) ([]int64, error) {
+	rows, err := c.db.QueryContext(ctx, "EXEC sys.sp_enumerrorlogs;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ret []int64
+	for rows.Next() {
+		var archive int64
+		var date, size interface{}
+		err = rows.Scan(&archive, &date, &size)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, archive)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return ret, nil
+}
+
+func (c *Client) readErrorLogLogins(ctx context.Context, archive int64) (map[string]time.Time, error) {
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-stored-procedures/sp-readerrorlog-transact-sql
+	rows, err := c.db.QueryContext(ctx, "EXEC sys.xp_readerrorlog @p1, 1, N'Login succeeded for user';", archive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ret := make(map[string]time.Time)
+	for rows.Next() {
+		var logDate time.Time
+		var processInfo, text string
+		err = rows.Scan(&logDate, &processInfo, &text)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := parseErrorLogLogin(text)
+		if !ok {
+			continue
+		}
+		if last, ok := ret[name]; !ok || logDate.After(last) {
+			ret[name] = logDate
+		}
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return ret, nil
+}
+
+// mergeLastLogins adds the logins of src to dst, keeping the latest time of logins found in both.
+func mergeLastLogins(dst map[string]time.Time, src map[string]time.Time) {
+	for name, t := range src {
+		if last, ok := dst[name]; !ok || t.After(last) {
+			dst[name] = t
+		}
+	}
+}
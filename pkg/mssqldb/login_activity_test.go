@@ -0,0 +1,45 @@
+package mssqldb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorLogLogin(t *testing.T) {
+	name, ok := parseErrorLogLogin("Login succeeded for user 'CONTOSO\\alice'. Connection made using Windows authentication. [CLIENT: 10.0.0.4]")
+	require.True(t, ok)
+	require.Equal(t, "CONTOSO\\alice", name)
+
+	_, ok = parseErrorLogLogin("Login failed for user 'bob'. Reason: Password did not match that for the login provided. [CLIENT: 10.0.0.4]")
+	require.False(t, ok)
+}
+
+func TestMergeLastLogins(t *testing.T) {
+	earlier := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	dst := map[string]time.Time{"alice": earlier, "bob": later}
+	mergeLastLogins(dst, map[string]time.Time{"alice": later, "bob": earlier, "carol": earlier})
+
+	require.Equal(t, map[string]time.Time{"alice": later, "bob": later, "carol": earlier}, dst)
+}
+
+func TestQuoteObjectName(t *testing.T) {
+	name, err := quoteObjectName("audit.dbo.login_activity")
+	require.NoError(t, err)
+	require.Equal(t, "[audit].[dbo].[login_activity]", name)
+
+	_, err = quoteObjectName("audit..login_activity")
+	require.Error(t, err)
+	_, err = quoteObjectName("logins; DROP TABLE x")
+	require.Error(t, err)
+}
+
+func TestLastLoginSourceValidate(t *testing.T) {
+	require.NoError(t, LastLoginSourceErrorLog.Validate(""))
+	require.NoError(t, LastLoginSourceTable.Validate("audit.dbo.login_activity"))
+	require.Error(t, LastLoginSourceAudit.Validate(""))
+	require.Error(t, LastLoginSource("trace").Validate(""))
+}
@@ -0,0 +1,175 @@
+package mssqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// ListLoginSessions returns the IDs of the sessions of a login, other than the connector's own session.
+func (c *Client) ListLoginSessions(ctx context.Context, login string) ([]int64, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing login sessions", zap.String("login", login))
+
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-dynamic-management-views/sys-dm-exec-sessions-transact-sql
+	query := `
+SELECT session_id
+FROM sys.dm_exec_sessions
+WHERE login_name = @p1 AND session_id <> @@SPID
+`
+
+	var ret []int64
+	err := c.db.SelectContext(ctx, &ret, query, login)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// KillSession terminates a session, rolling back its open transactions.
+func (c *Client) KillSession(ctx context.Context, sessionID int64) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("killing session", zap.Int64("session_id", sessionID))
+
+	command := fmt.Sprintf("KILL %d;", sessionID)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListOnlineDatabaseNames returns the names of the databases that can be accessed. Database snapshots are read-only
+// and are skipped. The database filter is not applied: it scopes the sync, not the cleanup of a deleted login.
+func (c *Client) ListOnlineDatabaseNames(ctx context.Context) ([]string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing online databases")
+
+	var ret []string
+	err := c.db.SelectContext(ctx, &ret, "SELECT name FROM sys.databases WHERE state_desc = 'ONLINE' AND source_database_id IS NULL ORDER BY database_id ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ListDatabasesOwnedByLogin returns the names of the databases owned by the login.
+func (c *Client) ListDatabasesOwnedByLogin(ctx context.Context, login string) ([]string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing databases owned by login", zap.String("login", login))
+
+	var ret []string
+	err := c.db.SelectContext(ctx, &ret, "SELECT name FROM sys.databases WHERE owner_sid = SUSER_SID(@p1) ORDER BY database_id ASC", login)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// JobModel is a SQL Server Agent job.
+type JobModel struct {
+	ID   string `db:"job_id"`
+	Name string `db:"name"`
+}
+
+// ListJobsOwnedByLogin returns the SQL Server Agent jobs owned by the login.
+// Azure SQL Database has no SQL Server Agent, so no jobs are returned there.
+func (c *Client) ListJobsOwnedByLogin(ctx context.Context, login string) ([]*JobModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing jobs owned by login", zap.String("login", login))
+
+	if c.azureSQLDatabase {
+		return nil, nil
+	}
+
+	query := `
+SELECT
+  CAST(job_id AS nvarchar(36)) AS job_id,
+  name
+FROM msdb.dbo.sysjobs
+WHERE owner_sid = SUSER_SID(@p1)
+`
+
+	var ret []*JobModel
+	err := c.db.SelectContext(ctx, &ret, query, login)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ChangeJobOwner transfers ownership of a SQL Server Agent job to the login.
+func (c *Client) ChangeJobOwner(ctx context.Context, jobID string, login string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("changing job owner", zap.String("job_id", jobID), zap.String("login", login))
+
+	_, err := c.db.ExecContext(ctx, "EXEC msdb.dbo.sp_update_job @job_id = @p1, @owner_login_name = @p2;", jobID, login)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// OwnedSecurableModel is a schema or role of a database owned by a database principal.
+type OwnedSecurableModel struct {
+	Name string `db:"name"`
+	// Class is the securable class used in ALTER AUTHORIZATION: SCHEMA, ROLE or APPLICATION ROLE.
+	Class string `db:"class"`
+}
+
+// ListSecurablesOwnedByDatabasePrincipal returns the schemas and roles of a database owned by the database principal,
+// which keep the principal from being dropped.
+func (c *Client) ListSecurablesOwnedByDatabasePrincipal(ctx context.Context, dbName string, principalID string) ([]*OwnedSecurableModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing securables owned by database principal", zap.String("dbName", dbName), zap.String("principal_id", principalID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT name, 'SCHEMA' AS class
+FROM [%s].sys.schemas
+WHERE principal_id = @p1
+UNION ALL
+SELECT name, CASE type WHEN 'A' THEN 'APPLICATION ROLE' ELSE 'ROLE' END AS class
+FROM [%s].sys.database_principals
+WHERE type IN ('R', 'A') AND owning_principal_id = @p1
+`, dbName, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []*OwnedSecurableModel
+	err = conn.SelectContext(ctx, &ret, query, principalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// ChangeSecurableOwner transfers ownership of a schema or role of a database to the database principal.
+func (c *Client) ChangeSecurableOwner(ctx context.Context, db string, securable *OwnedSecurableModel, principal string) error {
+	switch securable.Class {
+	case "SCHEMA", "ROLE", "APPLICATION ROLE":
+	default:
+		return fmt.Errorf("unexpected securable class: %s", securable.Class)
+	}
+
+	return c.alterAuthorization(ctx, db, securable.Class, securable.Name, principal)
+}
@@ -0,0 +1,514 @@
+package mssqldb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	TableType   = "table"
+	ViewType    = "view"
+	RoutineType = "routine"
+)
+
+var (
+	// TableObjectTypes are the sys.objects types listed as tables.
+	TableObjectTypes = []string{"U"}
+	// ViewObjectTypes are the sys.objects types listed as views.
+	ViewObjectTypes = []string{"V"}
+	// RoutineObjectTypes are the sys.objects types listed as routines: SQL and CLR procedures and functions.
+	RoutineObjectTypes = []string{"P", "PC", "FN", "IF", "TF", "FS", "FT"}
+)
+
+type ObjectModel struct {
+	ID         int64  `db:"object_id"`
+	Name       string `db:"name"`
+	SchemaID   int64  `db:"schema_id"`
+	SchemaName string `db:"schema_name"`
+	Type       string `db:"type"`
+	TypeDesc   string `db:"type_desc"`
+}
+
+func (c *Client) GetObject(ctx context.Context, dbName string, id string) (*ObjectModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting object", zap.String("id", id), zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  o.object_id,
+  o.name,
+  o.schema_id,
+  s.name AS schema_name,
+  RTRIM(o.type) AS type,
+  o.type_desc
+FROM [%s].sys.objects o
+JOIN [%s].sys.schemas s ON o.schema_id = s.schema_id
+WHERE o.object_id = @p1
+`, dbName, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, query, id)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var objectModel ObjectModel
+	err = row.StructScan(&objectModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectModel, nil
+}
+
+// ListObjects returns the objects of the given sys.objects types in a schema.
+// The patterns are matched against the object name qualified by its schema, e.g. 'Sales.Orders'.
+func (c *Client) ListObjects(
+	ctx context.Context,
+	dbName string,
+	schemaID string,
+	objectTypes []string,
+	patterns *NamePatterns,
+	pager *Pager,
+) ([]*ObjectModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing objects", zap.String("dbName", dbName), zap.String("schema_id", schemaID), zap.Strings("types", objectTypes))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, "", fmt.Errorf("invalid characters in dbName")
+	}
+
+	if len(objectTypes) == 0 {
+		return nil, "", fmt.Errorf("at least one object type is required")
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{offset, limit + 1, schemaID}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`
+SELECT
+  o.object_id,
+  o.name,
+  o.schema_id,
+  s.name AS schema_name,
+  RTRIM(o.type) AS type,
+  o.type_desc
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.objects o
+JOIN [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.schemas s ON o.schema_id = s.schema_id
+WHERE o.schema_id = @p3 AND o.is_ms_shipped = 0 AND o.type IN (`)
+	for i, objectType := range objectTypes {
+		if i > 0 {
+			_, _ = sb.WriteString(", ")
+		}
+		args = append(args, objectType)
+		_, _ = sb.WriteString(fmt.Sprintf("@p%d", len(args)))
+	}
+	_, _ = sb.WriteString(")")
+
+	var filter string
+	filter, args = patterns.whereClause("(s.name + '.' + o.name)", args)
+	_, _ = sb.WriteString(filter)
+
+	_, _ = sb.WriteString(`
+ORDER BY
+  o.object_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
+`)
+	l.Debug("ListObjects",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*ObjectModel
+	for rows.Next() {
+		var objectModel ObjectModel
+		err = rows.StructScan(&objectModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &objectModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+// ObjectPermissions returns the permissions that can be granted on objects of the given sys.objects type.
+func ObjectPermissions(objectType string) map[string]string {
+	if slices.Contains(RoutineObjectTypes, objectType) {
+		return RoutinePermissions
+	}
+	if slices.Contains(TableObjectTypes, objectType) || slices.Contains(ViewObjectTypes, objectType) {
+		return TablePermissions
+	}
+	return nil
+}
+
+func (c *Client) GrantPermissionOnObject(ctx context.Context, permission, db, schema, object, objectType, user string, withGrantOption bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"granting permission on object",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("object", object),
+		zap.String("user", user),
+		zap.Bool("with_grant_option", withGrantOption),
+	)
+
+	fullPermission, ok := ObjectPermissions(objectType)[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(object, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema, object or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; GRANT %s ON OBJECT::[%s].[%s] TO [%s]",
+		db,
+		fullPermission,
+		schema,
+		object,
+		user,
+	)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokePermissionOnObject removes an object permission from the user.
+// cascade must be set when the permission was granted WITH GRANT OPTION.
+func (c *Client) RevokePermissionOnObject(ctx context.Context, permission, db, schema, object, objectType, user string, cascade bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on object",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("object", object),
+		zap.String("user", user),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := ObjectPermissions(objectType)[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(object, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema, object or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; REVOKE %s ON OBJECT::[%s].[%s] FROM [%s]",
+		db,
+		fullPermission,
+		schema,
+		object,
+		user,
+	)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type ColumnModel struct {
+	ID       int64  `db:"column_id"`
+	Name     string `db:"name"`
+	IsMasked bool   `db:"is_masked"`
+}
+
+func (c *Client) ListColumns(ctx context.Context, dbName string, objectID string, pager *Pager) ([]*ColumnModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing columns", zap.String("dbName", dbName), zap.String("object_id", objectID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, "", fmt.Errorf("invalid characters in dbName")
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{objectID, offset, limit + 1}
+
+	var sb strings.Builder
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/sys-masked-columns-transact-sql
+	_, _ = sb.WriteString(`
+SELECT
+  cols.column_id,
+  cols.name,
+  CAST(CASE WHEN masked.column_id IS NULL THEN 0 ELSE 1 END AS bit) AS is_masked
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.columns cols
+LEFT JOIN [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.masked_columns masked
+  ON cols.object_id = masked.object_id AND cols.column_id = masked.column_id
+WHERE cols.object_id = @p1
+ORDER BY
+  cols.column_id ASC OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY
+`)
+	l.Debug("ListColumns",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*ColumnModel
+	for rows.Next() {
+		var columnModel ColumnModel
+		err = rows.StructScan(&columnModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &columnModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+func (c *Client) GrantPermissionOnColumn(ctx context.Context, permission, db, schema, object, column, user string, withGrantOption bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"granting permission on column",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("object", object),
+		zap.String("column", column),
+		zap.String("user", user),
+		zap.Bool("with_grant_option", withGrantOption),
+	)
+
+	fullPermission, ok := ColumnPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(object, "[]\"';") ||
+		strings.ContainsAny(column, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema, object, column or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; GRANT %s ON OBJECT::[%s].[%s] ([%s]) TO [%s]",
+		db,
+		fullPermission,
+		schema,
+		object,
+		column,
+		user,
+	)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DenyPermissionOnColumn(ctx context.Context, permission, db, schema, object, column, user string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"denying permission on column",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("object", object),
+		zap.String("column", column),
+		zap.String("user", user),
+	)
+
+	fullPermission, ok := ColumnPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(object, "[]\"';") ||
+		strings.ContainsAny(column, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema, object, column or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; DENY %s ON OBJECT::[%s].[%s] ([%s]) TO [%s];",
+		db,
+		fullPermission,
+		schema,
+		object,
+		column,
+		user,
+	)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokePermissionOnColumn removes a granted or denied column permission from the user.
+// cascade must be set when the permission was granted WITH GRANT OPTION.
+func (c *Client) RevokePermissionOnColumn(ctx context.Context, permission, db, schema, object, column, user string, cascade bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on column",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("object", object),
+		zap.String("column", column),
+		zap.String("user", user),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := ColumnPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(object, "[]\"';") ||
+		strings.ContainsAny(column, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema, object, column or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; REVOKE %s ON OBJECT::[%s].[%s] ([%s]) FROM [%s]",
+		db,
+		fullPermission,
+		schema,
+		object,
+		column,
+		user,
+	)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package mssqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+var ErrNoOwner = errors.New("no owner found")
+
+// OwnerModel is the principal that owns a database, schema or role.
+type OwnerModel struct {
+	ID   int64  `db:"principal_id"`
+	Name string `db:"name"`
+	Type string `db:"type"`
+}
+
+// GetDatabaseOwner returns the login that owns the database.
+// Returns ErrNoOwner if the owner SID does not match a login, e.g. for databases restored from another server.
+func (c *Client) GetDatabaseOwner(ctx context.Context, dbID int64) (*OwnerModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting database owner", zap.Int64("database_id", dbID))
+
+	query := `
+SELECT
+  sp.principal_id,
+  sp.name,
+  sp.type
+FROM sys.databases d
+JOIN sys.server_principals sp ON sp.sid = d.owner_sid
+WHERE d.database_id = @p1
+`
+
+	return c.getOwner(ctx, c.db, query, dbID)
+}
+
+// GetServerRoleOwner returns the server principal that owns the server role.
+func (c *Client) GetServerRoleOwner(ctx context.Context, roleID string) (*OwnerModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting server role owner", zap.String("role_id", roleID))
+
+	query := `
+SELECT
+  owner.principal_id,
+  owner.name,
+  owner.type
+FROM sys.server_principals r
+JOIN sys.server_principals owner ON owner.principal_id = r.owning_principal_id
+WHERE r.type = 'R' AND r.principal_id = @p1
+`
+
+	return c.getOwner(ctx, c.db, query, roleID)
+}
+
+// GetDatabaseRoleOwner returns the database principal that owns the database role.
+func (c *Client) GetDatabaseRoleOwner(ctx context.Context, dbName string, roleID string) (*OwnerModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting database role owner", zap.String("dbName", dbName), zap.String("role_id", roleID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  owner.principal_id,
+  owner.name,
+  owner.type
+FROM [%s].sys.database_principals r
+JOIN [%s].sys.database_principals owner ON owner.principal_id = r.owning_principal_id
+WHERE r.type = 'R' AND r.principal_id = @p1
+`, dbName, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getOwner(ctx, conn, query, roleID)
+}
+
+// GetSchemaOwner returns the database principal that owns the schema.
+func (c *Client) GetSchemaOwner(ctx context.Context, dbName string, schemaID string) (*OwnerModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting schema owner", zap.String("dbName", dbName), zap.String("schema_id", schemaID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  owner.principal_id,
+  owner.name,
+  owner.type
+FROM [%s].sys.schemas s
+JOIN [%s].sys.database_principals owner ON owner.principal_id = s.principal_id
+WHERE s.schema_id = @p1
+`, dbName, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getOwner(ctx, conn, query, schemaID)
+}
+
+func (c *Client) getOwner(ctx context.Context, conn *sqlx.DB, query string, args ...interface{}) (*OwnerModel, error) {
+	row := conn.QueryRowxContext(ctx, query, args...)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var ret OwnerModel
+	err := row.StructScan(&ret)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoOwner
+		}
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// ChangeDatabaseOwner transfers ownership of the database to the login.
+func (c *Client) ChangeDatabaseOwner(ctx context.Context, db, login string) error {
+	return c.alterAuthorization(ctx, "", "DATABASE", db, login)
+}
+
+// ChangeServerRoleOwner transfers ownership of the server role to the login.
+func (c *Client) ChangeServerRoleOwner(ctx context.Context, role, login string) error {
+	return c.alterAuthorization(ctx, "", "SERVER ROLE", role, login)
+}
+
+// ChangeDatabaseRoleOwner transfers ownership of the database role to the database user.
+func (c *Client) ChangeDatabaseRoleOwner(ctx context.Context, db, role, user string) error {
+	return c.alterAuthorization(ctx, db, "ROLE", role, user)
+}
+
+// ChangeSchemaOwner transfers ownership of the schema to the database user.
+func (c *Client) ChangeSchemaOwner(ctx context.Context, db, schema, user string) error {
+	return c.alterAuthorization(ctx, db, "SCHEMA", schema, user)
+}
+
+// alterAuthorization runs ALTER AUTHORIZATION for the securable, in the given database if db is set.
+func (c *Client) alterAuthorization(ctx context.Context, db, class, securable, principal string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"changing owner",
+		zap.String("db", db),
+		zap.String("class", class),
+		zap.String("securable", securable),
+		zap.String("principal", principal),
+	)
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, securable or principal")
+	}
+
+	conn := c.db
+	command := fmt.Sprintf("ALTER AUTHORIZATION ON %s::[%s] TO [%s];", class, securable, principal)
+	if db != "" {
+		command = fmt.Sprintf("USE [%s]; %s", db, command)
+
+		var err error
+		conn, err = c.databaseConn(ctx, db)
+		if err != nil {
+			return err
+		}
+	}
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package mssqldb
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -15,12 +16,20 @@ type PermissionModel struct {
 	PrincipalType string `db:"principal_type"`
 	State         string `db:"state"`
 	Permissions   string `db:"perms"`
+	// ColumnName is set for column-level permissions on objects.
+	ColumnName string `db:"column_name"`
 }
 
+// ListServerPermissions returns the server permissions granted to server principals.
+// Azure SQL Database has no server permissions, so none are returned there.
 func (c *Client) ListServerPermissions(ctx context.Context, pager *Pager) ([]*PermissionModel, string, error) {
 	l := ctxzap.Extract(ctx)
 	l.Debug("listing server permissions")
 
+	if c.azureSQLDatabase {
+		return nil, "", nil
+	}
+
 	offset, limit, err := pager.Parse()
 	if err != nil {
 		return nil, "", err
@@ -36,7 +45,7 @@ STRING_AGG(perms.type, ',') as perms,
 principals.type as principal_type 
 FROM sys.server_permissions perms 
          JOIN sys.server_principals principals ON perms.grantee_principal_id = principals.principal_id 
-WHERE perms.state = 'G' OR perms.state = 'W' 
+WHERE perms.state IN ('G', 'W', 'D') AND perms.class = 100 
 GROUP BY perms.grantee_principal_id, perms.state, principals.name, principals.type 
 ORDER BY perms.grantee_principal_id ASC 
 OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`)
@@ -73,6 +82,68 @@ OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`)
 	return ret, nextPageToken, nil
 }
 
+// ListServerPrincipalPermissions returns the permissions granted on a login or server role (class 101 securables).
+// Azure SQL Database has no server permissions, so none are returned there.
+func (c *Client) ListServerPrincipalPermissions(ctx context.Context, principalID string, pager *Pager) ([]*PermissionModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing server principal permissions", zap.String("principal_id", principalID))
+
+	if c.azureSQLDatabase {
+		return nil, "", nil
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{principalID, offset, limit + 1}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`SELECT 
+principals.name as principal_name, 
+perms.grantee_principal_id as principal_id, 
+perms.state as state, 
+STRING_AGG(perms.type, ',') as perms, 
+principals.type as principal_type 
+FROM sys.server_permissions perms 
+         JOIN sys.server_principals principals ON perms.grantee_principal_id = principals.principal_id 
+WHERE perms.state IN ('G', 'W', 'D') AND perms.class = 101 AND perms.major_id = @p1 
+GROUP BY perms.grantee_principal_id, perms.state, principals.name, principals.type 
+ORDER BY perms.grantee_principal_id ASC 
+OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY`)
+	l.Debug("ListServerPrincipalPermissions",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*PermissionModel
+	for rows.Next() {
+		var spModel PermissionModel
+		err = rows.StructScan(&spModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &spModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
 func (c *Client) ListDatabasePermissions(ctx context.Context, dbName string, pager *Pager) ([]*PermissionModel, string, error) {
 	l := ctxzap.Extract(ctx)
 	l.Debug("listing database permissions")
@@ -97,7 +168,7 @@ FROM [`)
 	_, _ = sb.WriteString(dbName)
 	_, _ = sb.WriteString(`].sys.database_principals AS principals 
              ON perms.grantee_principal_id = principals.principal_id 
-WHERE (perms.state = 'G' OR perms.state = 'W') AND (perms.class = 0 AND perms.major_id = 0) 
+WHERE perms.state IN ('G', 'W', 'D') AND (perms.class = 0 AND perms.major_id = 0) 
 GROUP BY perms.grantee_principal_id, perms.state, principals.name, principals.type 
 ORDER BY perms.grantee_principal_id ASC 
 OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`)
@@ -105,7 +176,83 @@ OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`)
 		zap.String("sql query", sb.String()),
 		zap.Any("args", args),
 	)
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*PermissionModel
+	for rows.Next() {
+		var dpModel PermissionModel
+		err = rows.StructScan(&dpModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &dpModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+// ListDatabasePrincipalPermissions returns the permissions granted on a database user, role or application role (class 4 securables).
+func (c *Client) ListDatabasePrincipalPermissions(ctx context.Context, dbName string, principalID string, pager *Pager) ([]*PermissionModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing database principal permissions", zap.String("dbName", dbName), zap.String("principal_id", principalID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, "", fmt.Errorf("invalid characters in dbName")
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{principalID, offset, limit + 1}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`SELECT
+    principals.name as principal_name,
+    perms.grantee_principal_id as principal_id,
+    perms.state as state,
+    STRING_AGG(perms.type, ',') as perms,
+    principals.type as principal_type
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_permissions perms
+         JOIN [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals AS principals 
+             ON perms.grantee_principal_id = principals.principal_id 
+WHERE perms.state IN ('G', 'W', 'D') AND perms.class = 4 AND perms.major_id = @p1 
+GROUP BY perms.grantee_principal_id, perms.state, principals.name, principals.type 
+ORDER BY perms.grantee_principal_id ASC 
+OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY`)
+	l.Debug("ListDatabasePrincipalPermissions",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -133,3 +280,141 @@ OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`)
 
 	return ret, nextPageToken, nil
 }
+
+func (c *Client) ListSchemaPermissions(ctx context.Context, dbName string, schemaID string, pager *Pager) ([]*PermissionModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing schema permissions", zap.String("dbName", dbName), zap.String("schema_id", schemaID))
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{schemaID, offset, limit + 1}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`SELECT
+    principals.name as principal_name,
+    perms.grantee_principal_id as principal_id,
+    perms.state as state,
+    STRING_AGG(perms.type, ',') as perms,
+    principals.type as principal_type
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_permissions perms
+         JOIN [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals AS principals 
+             ON perms.grantee_principal_id = principals.principal_id 
+WHERE (perms.state = 'G' OR perms.state = 'W') AND (perms.class = 3 AND perms.major_id = @p1) 
+GROUP BY perms.grantee_principal_id, perms.state, principals.name, principals.type 
+ORDER BY perms.grantee_principal_id ASC 
+OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY`)
+	l.Debug("ListSchemaPermissions",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*PermissionModel
+	for rows.Next() {
+		var spModel PermissionModel
+		err = rows.StructScan(&spModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &spModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+// ListObjectPermissions returns the permissions granted on an object, including the permissions granted or denied on its columns.
+func (c *Client) ListObjectPermissions(ctx context.Context, dbName string, objectID string, pager *Pager) ([]*PermissionModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing object permissions", zap.String("dbName", dbName), zap.String("object_id", objectID))
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{objectID, offset, limit + 1}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`SELECT
+    principals.name as principal_name,
+    perms.grantee_principal_id as principal_id,
+    perms.state as state,
+    STRING_AGG(perms.type, ',') as perms,
+    principals.type as principal_type,
+    ISNULL(cols.name, '') as column_name
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_permissions perms
+         JOIN [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals AS principals 
+             ON perms.grantee_principal_id = principals.principal_id 
+         LEFT JOIN [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.columns AS cols 
+             ON perms.major_id = cols.object_id AND perms.minor_id = cols.column_id 
+WHERE (perms.state = 'G' OR perms.state = 'W' OR (perms.state = 'D' AND perms.minor_id <> 0)) AND (perms.class = 1 AND perms.major_id = @p1) 
+GROUP BY perms.grantee_principal_id, perms.state, principals.name, principals.type, cols.name 
+ORDER BY perms.grantee_principal_id ASC, perms.state ASC, cols.name ASC 
+OFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY`)
+	l.Debug("ListObjectPermissions",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*PermissionModel
+	for rows.Next() {
+		var opModel PermissionModel
+		err = rows.StructScan(&opModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &opModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
@@ -0,0 +1,72 @@
+package mssqldb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPrincipalFiltered is returned when provisioning a server principal that is excluded from the sync.
+var ErrPrincipalFiltered = errors.New("principal is excluded by the principal filter")
+
+// systemPrincipalPatterns match the server principals created by SQL Server: the ##...## certificate logins and
+// roles, the built-in Windows accounts and service SIDs, and the fixed server roles.
+// Server principal names are unique across logins and roles, so the role names cannot match a login.
+var systemPrincipalPatterns = []string{
+	"##*##",
+	`NT SERVICE\*`,
+	`NT AUTHORITY\*`,
+	`BUILTIN\*`,
+	"public",
+	"sysadmin",
+	"securityadmin",
+	"serveradmin",
+	"setupadmin",
+	"processadmin",
+	"diskadmin",
+	"dbcreator",
+	"bulkadmin",
+}
+
+// PrincipalFilter selects the server principals (logins, groups and server roles) that are synced.
+// Excluded principals are not listed, are left out of grants and cannot be provisioned.
+type PrincipalFilter struct {
+	// Include and Exclude are glob patterns matched against principal names.
+	Include []string
+	Exclude []string
+	// ExcludeSystem excludes the principals created by SQL Server. The sa login is kept.
+	ExcludeSystem bool
+}
+
+// patterns returns the name patterns the filter applies.
+func (f *PrincipalFilter) patterns() *NamePatterns {
+	if f == nil {
+		return nil
+	}
+
+	exclude := f.Exclude
+	if f.ExcludeSystem {
+		exclude = append(append([]string{}, f.Exclude...), systemPrincipalPatterns...)
+	}
+
+	return &NamePatterns{
+		Include: f.Include,
+		Exclude: exclude,
+	}
+}
+
+// Allows reports whether the principal with the given name passes the filter.
+func (f *PrincipalFilter) Allows(name string) bool {
+	return f.patterns().Match(name)
+}
+
+// PrincipalAllowed reports whether the server principal with the given name is synced.
+func (c *Client) PrincipalAllowed(name string) bool {
+	return c.principalFilter.Allows(name)
+}
+
+func (c *Client) checkPrincipalAllowed(name string) error {
+	if !c.PrincipalAllowed(name) {
+		return fmt.Errorf("%w: %s", ErrPrincipalFiltered, name)
+	}
+	return nil
+}
@@ -14,10 +14,11 @@ const ServerRoleType = "server-role"
 const DatabaseRoleType = "database-role"
 
 type RoleModel struct {
-	ID         int64  `db:"principal_id"`
-	SecurityID string `db:"sid"`
-	Name       string `db:"name"`
-	Type       string `db:"type_desc"`
+	ID          int64  `db:"principal_id"`
+	SecurityID  string `db:"sid"`
+	Name        string `db:"name"`
+	Type        string `db:"type_desc"`
+	IsFixedRole bool   `db:"is_fixed_role"`
 }
 
 type RolePrincipalModel struct {
@@ -102,10 +103,17 @@ SELECT
   principal_id,
   sid,
   name, 
-  type_desc 
+  type_desc,
+  is_fixed_role 
 FROM 
   sys.server_principals 
-WHERE type = 'R' 
+WHERE type = 'R' `)
+
+	var filter string
+	filter, args = c.principalFilter.patterns().whereClause("name", args)
+	_, _ = sb.WriteString(filter)
+
+	_, _ = sb.WriteString(`
 ORDER BY 
   principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
 `)
@@ -159,7 +167,8 @@ SELECT
   principal_id, 
   sid,
   name, 
-  type_desc 
+  type_desc,
+  is_fixed_role 
 FROM [`)
 	_, _ = sb.WriteString(dbName)
 	_, _ = sb.WriteString(`].sys.database_principals 
@@ -171,7 +180,12 @@ ORDER BY
 		zap.String("sql query", sb.String()),
 		zap.Any("args", args),
 	)
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -234,7 +248,12 @@ func (c *Client) ListDatabaseRolePrincipals(ctx context.Context, dbName string,
 		zap.String("sql query", query),
 		zap.Any("args", args),
 	)
-	rows, err := c.db.QueryxContext(ctx, query, args...)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -272,7 +291,8 @@ SELECT
   principal_id, 
   sid,
   name, 
-  type_desc 
+  type_desc,
+  is_fixed_role 
 	FROM 
 sys.server_principals 
 WHERE type = 'R' AND principal_id = @p1
@@ -289,7 +309,12 @@ WHERE type = 'R' AND principal_id = @p1
 		return nil, err
 	}
 
-	return &roleModel, err
+	err = c.checkPrincipalAllowed(roleModel.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roleModel, nil
 }
 
 func (c *Client) GetDatabaseRole(ctx context.Context, dbName string, id string) (*RoleModel, error) {
@@ -305,7 +330,8 @@ SELECT
   principal_id, 
   sid,
   name, 
-  type_desc 
+  type_desc,
+  is_fixed_role 
 	FROM 
 [%s].sys.database_principals 
 WHERE type = 'R' AND principal_id = @p1
@@ -316,13 +342,18 @@ WHERE type = 'R' AND principal_id = @p1
 		dbName,
 	)
 
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
 	var roleModel RoleModel
-	row := c.db.QueryRowxContext(ctx, query, id)
+	row := conn.QueryRowxContext(ctx, query, id)
 	if err := row.Err(); err != nil {
 		return nil, err
 	}
 
-	err := row.StructScan(&roleModel)
+	err = row.StructScan(&roleModel)
 	if err != nil {
 		return nil, err
 	}
@@ -343,9 +374,21 @@ func (c *Client) AddUserToServerRole(ctx context.Context, role string, userID st
 		return fmt.Errorf("cannot get user: %w", err)
 	}
 
-	query := fmt.Sprintf(`ALTER SERVER ROLE [%s] ADD MEMBER [%s];`, role, user.Name)
+	return c.AddMemberToServerRole(ctx, role, user.Name)
+}
+
+// AddMemberToServerRole adds a login or another server role to a server role.
+func (c *Client) AddMemberToServerRole(ctx context.Context, role string, member string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("adding member to server role", zap.String("role", role), zap.String("member", member))
+
+	if strings.ContainsAny(role, "[]\"';") || strings.ContainsAny(member, "[]\"';") {
+		return fmt.Errorf("invalid characters in role or member")
+	}
+
+	query := fmt.Sprintf(`ALTER SERVER ROLE [%s] ADD MEMBER [%s];`, role, member)
 
-	_, err = c.db.ExecContext(ctx, query)
+	_, err := c.db.ExecContext(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -361,7 +404,12 @@ func (c *Client) AddUserToDatabaseRole(ctx context.Context, role string, db stri
 	}
 
 	query := fmt.Sprintf(`USE [%s]; ALTER ROLE [%s] ADD MEMBER [%s];`, db, role, user)
-	_, err := c.db.ExecContext(ctx, query)
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -402,10 +450,299 @@ ALTER ROLE [%s] DROP MEMBER [%s];`, db, role, user)
 
 	l.Debug("RevokeUserToDatabaseRole", zap.String("sql query", query))
 
-	_, err := c.db.ExecContext(ctx, query)
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PublicRoleName is the role every principal belongs to, both on the server and in every database.
+// It is not flagged as a fixed role but cannot be dropped.
+const PublicRoleName = "public"
+
+func (c *Client) GetServerRoleByName(ctx context.Context, name string) (*RoleModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting server role by name", zap.String("name", name))
+
+	query := `
+SELECT
+  principal_id,
+  sid,
+  name,
+  type_desc,
+  is_fixed_role
+FROM sys.server_principals
+WHERE type = 'R' AND name = @p1
+`
+
+	var roleModel RoleModel
+	row := c.db.QueryRowxContext(ctx, query, name)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	err := row.StructScan(&roleModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roleModel, nil
+}
+
+func (c *Client) GetDatabaseRoleByName(ctx context.Context, dbName string, name string) (*RoleModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting database role by name", zap.String("name", name), zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  principal_id,
+  sid,
+  name,
+  type_desc,
+  is_fixed_role
+FROM [%s].sys.database_principals
+WHERE type = 'R' AND name = @p1
+`, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleModel RoleModel
+	row := conn.QueryRowxContext(ctx, query, name)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	err = row.StructScan(&roleModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roleModel, nil
+}
+
+// CreateServerRole creates a user-defined server role. The role is owned by the given login, or by the connector's login if owner is empty.
+func (c *Client) CreateServerRole(ctx context.Context, name string, owner string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("creating server role", zap.String("name", name), zap.String("owner", owner))
+
+	if strings.ContainsAny(name, "[]\"';") || strings.ContainsAny(owner, "[]\"';") {
+		return fmt.Errorf("invalid characters in name or owner")
+	}
+
+	command := fmt.Sprintf("CREATE SERVER ROLE [%s]", name)
+	if owner != "" {
+		command += fmt.Sprintf(" AUTHORIZATION [%s]", owner)
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// CreateDatabaseRole creates a user-defined database role. The role is owned by the given database principal,
+// or by the connector's database user if owner is empty.
+func (c *Client) CreateDatabaseRole(ctx context.Context, db string, name string, owner string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("creating database role", zap.String("db", db), zap.String("name", name), zap.String("owner", owner))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(name, "[]\"';") || strings.ContainsAny(owner, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, name or owner")
+	}
+
+	command := fmt.Sprintf("USE [%s]; CREATE ROLE [%s]", db, name)
+	if owner != "" {
+		command += fmt.Sprintf(" AUTHORIZATION [%s]", owner)
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DropServerRole(ctx context.Context, name string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("dropping server role", zap.String("name", name))
+
+	if strings.ContainsAny(name, "[]\"';") {
+		return fmt.Errorf("invalid characters in name")
+	}
+
+	command := fmt.Sprintf("DROP SERVER ROLE [%s];", name)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DropDatabaseRole(ctx context.Context, db string, name string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("dropping database role", zap.String("db", db), zap.String("name", name))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(name, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName or name")
+	}
+
+	command := fmt.Sprintf("USE [%s]; DROP ROLE [%s];", db, name)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountServerRoleMembers returns the number of members of a server role.
+func (c *Client) CountServerRoleMembers(ctx context.Context, roleID string) (int, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("counting server role members", zap.String("role_id", roleID))
+
+	query := `
+SELECT COUNT(*)
+FROM sys.server_role_members
+WHERE role_principal_id = @p1
+`
+
+	var count int
+	err := c.db.QueryRowxContext(ctx, query, roleID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountDatabaseRoleMembers returns the number of members of a database role.
+func (c *Client) CountDatabaseRoleMembers(ctx context.Context, dbName string, roleID string) (int, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("counting database role members", zap.String("dbName", dbName), zap.String("role_id", roleID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return 0, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT COUNT(*)
+FROM [%s].sys.database_role_members
+WHERE role_principal_id = @p1
+`, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = conn.QueryRowxContext(ctx, query, roleID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// IsServerRoleMember reports whether the principal is a member of the server role, directly or through nested roles.
+func (c *Client) IsServerRoleMember(ctx context.Context, roleID string, principalID string) (bool, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("checking server role membership", zap.String("role_id", roleID), zap.String("principal_id", principalID))
+
+	query := `
+WITH members AS (
+  SELECT member_principal_id
+  FROM sys.server_role_members
+  WHERE role_principal_id = @p1
+  UNION ALL
+  SELECT rm.member_principal_id
+  FROM sys.server_role_members rm
+  JOIN members m ON rm.role_principal_id = m.member_principal_id
+)
+SELECT COUNT(*) FROM members WHERE member_principal_id = @p2
+`
+
+	var count int
+	err := c.db.QueryRowxContext(ctx, query, roleID, principalID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IsDatabaseRoleMember reports whether the principal is a member of the database role, directly or through nested roles.
+func (c *Client) IsDatabaseRoleMember(ctx context.Context, dbName string, roleID string, principalID string) (bool, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("checking database role membership", zap.String("dbName", dbName), zap.String("role_id", roleID), zap.String("principal_id", principalID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return false, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+WITH members AS (
+  SELECT member_principal_id
+  FROM [%s].sys.database_role_members
+  WHERE role_principal_id = @p1
+  UNION ALL
+  SELECT rm.member_principal_id
+  FROM [%s].sys.database_role_members rm
+  JOIN members m ON rm.role_principal_id = m.member_principal_id
+)
+SELECT COUNT(*) FROM members WHERE member_principal_id = @p2
+`, dbName, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	err = conn.QueryRowxContext(ctx, query, roleID, principalID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
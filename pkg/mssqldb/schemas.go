@@ -0,0 +1,249 @@
+package mssqldb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const SchemaType = "schema"
+
+type SchemaModel struct {
+	ID          int64  `db:"schema_id"`
+	Name        string `db:"name"`
+	PrincipalID int64  `db:"principal_id"`
+}
+
+func (c *Client) GetSchema(ctx context.Context, dbName string, id string) (*SchemaModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting schema", zap.String("id", id), zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT
+  schema_id,
+  name,
+  principal_id
+FROM
+  [%s].sys.schemas
+WHERE schema_id = @p1
+`, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, query, id)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var schemaModel SchemaModel
+	err = row.StructScan(&schemaModel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaModel, nil
+}
+
+func (c *Client) ListSchemas(ctx context.Context, dbName string, pager *Pager) ([]*SchemaModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing schemas", zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, "", fmt.Errorf("invalid characters in dbName")
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{offset, limit + 1}
+
+	var sb strings.Builder
+	// Schemas owned by the fixed database roles (db_owner, db_datareader, ...) have IDs from 16384 to 16399
+	// and only exist for backwards compatibility, so they are skipped.
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/schemas-catalog-views-sys-schemas
+	_, _ = sb.WriteString(`
+SELECT
+  schema_id,
+  name,
+  principal_id
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.schemas
+WHERE schema_id NOT BETWEEN 16384 AND 16399
+ORDER BY
+  schema_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
+`)
+	l.Debug("ListSchemas",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*SchemaModel
+	for rows.Next() {
+		var schemaModel SchemaModel
+		err = rows.StructScan(&schemaModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &schemaModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+func (c *Client) GrantPermissionOnSchema(ctx context.Context, permission, db, schema, user string, withGrantOption bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"granting permission on schema",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("user", user),
+		zap.Bool("with_grant_option", withGrantOption),
+	)
+
+	fullPermission, ok := SchemaPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; GRANT %s ON SCHEMA::[%s] TO [%s]",
+		db,
+		fullPermission,
+		schema,
+		user,
+	)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokePermissionOnSchema removes a schema permission from the user.
+// cascade must be set when the permission was granted WITH GRANT OPTION.
+func (c *Client) RevokePermissionOnSchema(ctx context.Context, permission, db, schema, user string, cascade bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on schema",
+		zap.String("permission", permission),
+		zap.String("db", db),
+		zap.String("schema", schema),
+		zap.String("user", user),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := SchemaPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(schema, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, schema or user")
+	}
+
+	command := fmt.Sprintf(
+		"USE [%s]; REVOKE %s ON SCHEMA::[%s] FROM [%s]",
+		db,
+		fullPermission,
+		schema,
+		user,
+	)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountSchemasOwnedBy returns the number of schemas of a database owned by the given database principal.
+func (c *Client) CountSchemasOwnedBy(ctx context.Context, dbName string, principalID string) (int, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("counting schemas owned by principal", zap.String("dbName", dbName), zap.String("principal_id", principalID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return 0, fmt.Errorf("invalid characters in dbName")
+	}
+
+	query := fmt.Sprintf(`
+SELECT COUNT(*)
+FROM [%s].sys.schemas
+WHERE principal_id = @p1
+`, dbName)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = conn.QueryRowxContext(ctx, query, principalID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
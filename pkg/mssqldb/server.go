@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 const ServerType = "server"
@@ -49,3 +50,267 @@ DROP LOGIN [%s];`, userName)
 	}
 	return nil
 }
+
+// serverPermissionStatements maps the sys.server_permissions type codes to the permission names used in GRANT statements.
+// CONNECT (CO) only applies to endpoints and cannot be granted at the server level.
+var serverPermissionStatements = map[string]string{
+	"AAES": "ALTER ANY EVENT SESSION",
+	"ADBO": "ADMINISTER BULK OPERATIONS",
+	"ALAA": "ALTER ANY SERVER AUDIT",
+	"ALAG": "ALTER ANY AVAILABILITY GROUP",
+	"ALCD": "ALTER ANY CREDENTIAL",
+	"ALCO": "ALTER ANY CONNECTION",
+	"ALDB": "ALTER ANY DATABASE",
+	"ALES": "ALTER ANY EVENT NOTIFICATION",
+	"ALLG": "ALTER ANY LOGIN",
+	"ALLS": "ALTER ANY LINKED SERVER",
+	"ALRS": "ALTER RESOURCES",
+	"ALSR": "ALTER ANY SERVER ROLE",
+	"ALSS": "ALTER SERVER STATE",
+	"ALST": "ALTER SETTINGS",
+	"ALTR": "ALTER TRACE",
+	"AUTH": "AUTHENTICATE SERVER",
+	"CADB": "CONNECT ANY DATABASE",
+	"CL":   "CONTROL SERVER",
+	"COSQ": "CONNECT SQL",
+	"CRAC": "CREATE AVAILABILITY GROUP",
+	"CRDB": "CREATE ANY DATABASE",
+	"CRDE": "CREATE DDL EVENT NOTIFICATION",
+	"CRHE": "CREATE ENDPOINT",
+	"CRSR": "CREATE SERVER ROLE",
+	"CRTE": "CREATE TRACE EVENT NOTIFICATION",
+	"IAL":  "IMPERSONATE ANY LOGIN",
+	"SHDN": "SHUTDOWN",
+	"SUS":  "SELECT ALL USER SECURABLES",
+	"VW":   "VIEW ANY DEFINITION",
+	"VWDB": "VIEW ANY DATABASE",
+	"VWSS": "VIEW SERVER STATE",
+	"XA":   "EXTERNAL ACCESS ASSEMBLY",
+	"XU":   "UNSAFE ASSEMBLY",
+}
+
+func (c *Client) GrantPermissionOnServer(ctx context.Context, permission, principal string, withGrantOption bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"granting permission on server",
+		zap.String("permission", permission),
+		zap.String("principal", principal),
+		zap.Bool("with_grant_option", withGrantOption),
+	)
+
+	fullPermission, ok := serverPermissionStatements[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in principal")
+	}
+
+	command := fmt.Sprintf("GRANT %s TO [%s]", fullPermission, principal)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DenyPermissionOnServer(ctx context.Context, permission, principal string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"denying permission on server",
+		zap.String("permission", permission),
+		zap.String("principal", principal),
+	)
+
+	fullPermission, ok := serverPermissionStatements[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in principal")
+	}
+
+	command := fmt.Sprintf("DENY %s TO [%s];", fullPermission, principal)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RevokePermissionOnServer removes a granted or denied server permission from the principal.
+// cascade must be set when the permission was granted WITH GRANT OPTION, which also revokes it from
+// every principal the grantee passed it on to.
+func (c *Client) RevokePermissionOnServer(ctx context.Context, permission, principal string, cascade bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on server",
+		zap.String("permission", permission),
+		zap.String("principal", principal),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := serverPermissionStatements[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in principal")
+	}
+
+	command := fmt.Sprintf("REVOKE %s FROM [%s]", fullPermission, principal)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ServerPrincipalClass is the class name used to reference a login or server role in a GRANT statement.
+type ServerPrincipalClass string
+
+const (
+	ServerPrincipalClassLogin      ServerPrincipalClass = "LOGIN"
+	ServerPrincipalClassServerRole ServerPrincipalClass = "SERVER ROLE"
+)
+
+// GrantPermissionOnServerPrincipal grants a permission on a login or server role, e.g. IMPERSONATE ON LOGIN::[sa].
+func (c *Client) GrantPermissionOnServerPrincipal(
+	ctx context.Context,
+	permission string,
+	class ServerPrincipalClass,
+	securable string,
+	principal string,
+	withGrantOption bool,
+) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"granting permission on server principal",
+		zap.String("permission", permission),
+		zap.String("class", string(class)),
+		zap.String("securable", securable),
+		zap.String("principal", principal),
+		zap.Bool("with_grant_option", withGrantOption),
+	)
+
+	fullPermission, ok := ServerPrincipalPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in securable or principal")
+	}
+
+	command := fmt.Sprintf("GRANT %s ON %s::[%s] TO [%s]", fullPermission, class, securable, principal)
+	if withGrantOption {
+		command += " WITH GRANT OPTION"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) DenyPermissionOnServerPrincipal(ctx context.Context, permission string, class ServerPrincipalClass, securable, principal string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"denying permission on server principal",
+		zap.String("permission", permission),
+		zap.String("class", string(class)),
+		zap.String("securable", securable),
+		zap.String("principal", principal),
+	)
+
+	fullPermission, ok := ServerPrincipalPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in securable or principal")
+	}
+
+	command := fmt.Sprintf("DENY %s ON %s::[%s] TO [%s];", fullPermission, class, securable, principal)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) RevokePermissionOnServerPrincipal(
+	ctx context.Context,
+	permission string,
+	class ServerPrincipalClass,
+	securable string,
+	principal string,
+	cascade bool,
+) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug(
+		"revoking permission on server principal",
+		zap.String("permission", permission),
+		zap.String("class", string(class)),
+		zap.String("securable", securable),
+		zap.String("principal", principal),
+		zap.Bool("cascade", cascade),
+	)
+
+	fullPermission, ok := ServerPrincipalPermissions[strings.ToUpper(permission)]
+	if !ok {
+		return fmt.Errorf("permission %s is not allowed", permission)
+	}
+
+	if strings.ContainsAny(securable, "[]\"';") || strings.ContainsAny(principal, "[]\"';") {
+		return fmt.Errorf("invalid characters in securable or principal")
+	}
+
+	command := fmt.Sprintf("REVOKE %s ON %s::[%s] FROM [%s]", fullPermission, class, securable, principal)
+	if cascade {
+		command += " CASCADE"
+	}
+	command += ";"
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -26,8 +27,87 @@ type UserModel struct {
 	Name       string `db:"name"`
 	Type       string `db:"type_desc"`
 	IsDisabled bool   `db:"is_disabled"`
+	// IsLocked is set for SQL logins locked out by the password policy after too many failed logins.
+	IsLocked        bool           `db:"is_locked"`
+	CreateDate      time.Time      `db:"create_date"`
+	ModifyDate      time.Time      `db:"modify_date"`
+	DefaultDatabase sql.NullString `db:"default_database_name"`
+	DefaultLanguage sql.NullString `db:"default_language_name"`
+	// The password properties are only set for SQL logins.
+	IsPolicyChecked     sql.NullBool  `db:"is_policy_checked"`
+	IsExpirationChecked sql.NullBool  `db:"is_expiration_checked"`
+	IsExpired           sql.NullBool  `db:"is_expired"`
+	IsMustChange        sql.NullBool  `db:"is_must_change"`
+	PasswordLastSetTime sql.NullTime  `db:"password_last_set_time"`
+	DaysUntilExpiration sql.NullInt64 `db:"days_until_expiration"`
+	BadPasswordCount    sql.NullInt64 `db:"bad_password_count"`
+	BadPasswordTime     sql.NullTime  `db:"bad_password_time"`
+	LockoutTime         sql.NullTime  `db:"lockout_time"`
 }
 
+// loginColumns selects the columns of UserModel from loginTables.
+// LOGINPROPERTY returns NULL for logins that are not SQL logins, and so does the join with sys.sql_logins.
+// https://learn.microsoft.com/en-us/sql/t-sql/functions/loginproperty-transact-sql
+const loginColumns = `
+  sp.principal_id,
+  sp.sid,
+  sp.name,
+  sp.type_desc,
+  sp.is_disabled,
+  CAST(ISNULL(CAST(LOGINPROPERTY(sp.name, 'IsLocked') AS int), 0) AS bit) AS is_locked,
+  sp.create_date,
+  sp.modify_date,
+  sp.default_database_name,
+  sp.default_language_name,
+  sl.is_policy_checked,
+  sl.is_expiration_checked,
+  CAST(CAST(LOGINPROPERTY(sp.name, 'IsExpired') AS int) AS bit) AS is_expired,
+  CAST(CAST(LOGINPROPERTY(sp.name, 'IsMustChange') AS int) AS bit) AS is_must_change,
+  CAST(LOGINPROPERTY(sp.name, 'PasswordLastSetTime') AS datetime) AS password_last_set_time,
+  CAST(LOGINPROPERTY(sp.name, 'DaysUntilExpiration') AS int) AS days_until_expiration,
+  CAST(LOGINPROPERTY(sp.name, 'BadPasswordCount') AS int) AS bad_password_count,
+  CAST(LOGINPROPERTY(sp.name, 'BadPasswordTime') AS datetime) AS bad_password_time,
+  CAST(LOGINPROPERTY(sp.name, 'LockoutTime') AS datetime) AS lockout_time
+`
+
+// azureLoginColumns are the loginColumns of Azure SQL Database, which does not support LOGINPROPERTY.
+const azureLoginColumns = `
+  sp.principal_id,
+  sp.sid,
+  sp.name,
+  sp.type_desc,
+  sp.is_disabled,
+  CAST(0 AS bit) AS is_locked,
+  sp.create_date,
+  sp.modify_date,
+  sp.default_database_name,
+  sp.default_language_name,
+  sl.is_policy_checked,
+  sl.is_expiration_checked,
+  CAST(NULL AS bit) AS is_expired,
+  CAST(NULL AS bit) AS is_must_change,
+  CAST(NULL AS datetime) AS password_last_set_time,
+  CAST(NULL AS int) AS days_until_expiration,
+  CAST(NULL AS int) AS bad_password_count,
+  CAST(NULL AS datetime) AS bad_password_time,
+  CAST(NULL AS datetime) AS lockout_time
+`
+
+// userLoginColumns returns the login columns supported by the server.
+func (c *Client) userLoginColumns() string {
+	if c.azureSQLDatabase {
+		return azureLoginColumns
+	}
+	return loginColumns
+}
+
+// loginTables joins the server principals with the SQL login properties.
+// https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/sys-sql-logins-transact-sql
+const loginTables = `
+  sys.server_principals sp
+  LEFT JOIN sys.sql_logins sl ON sl.principal_id = sp.principal_id
+`
+
 type UserDBModel struct {
 	ID                  string `db:"principal_id"`
 	DatabasePrincipalId string `db:"database_principal_id"`
@@ -53,24 +133,26 @@ func (c *Client) ListServerUserPrincipals(ctx context.Context, pager *Pager) ([]
 	// Fetch the user principals.
 	// https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/sys-server-principals-transact-sql
 	_, _ = sb.WriteString(`
-SELECT 
-  principal_id,
-  sid,
-  name, 
-  type_desc,
-  is_disabled
-FROM 
-  sys.server_principals
-WHERE 
+SELECT`)
+	_, _ = sb.WriteString(c.userLoginColumns())
+	_, _ = sb.WriteString(`FROM`)
+	_, _ = sb.WriteString(loginTables)
+	_, _ = sb.WriteString(`WHERE 
   (
-    type = 'S' 
-    OR type = 'U' 
-    OR type = 'C' 
-    or type = 'E' 
-    or type = 'K'
-  ) 
+    sp.type = 'S' 
+    OR sp.type = 'U' 
+    OR sp.type = 'C' 
+    or sp.type = 'E' 
+    or sp.type = 'K'
+  ) `)
+
+	var filter string
+	filter, args = c.principalFilter.patterns().whereClause("sp.name", args)
+	_, _ = sb.WriteString(filter)
+
+	_, _ = sb.WriteString(`
 ORDER BY 
-  principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
+  sp.principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
 `)
 
 	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
@@ -104,12 +186,30 @@ ORDER BY
 
 // GetServerPrincipalForDatabasePrincipal returns the server principal for a given database user.
 // Returns ErrNoServerPrincipal if no server principal is found.
+// The SID of the database user is read first, so the database and server catalogs can be on different connections.
 func (c *Client) GetServerPrincipalForDatabasePrincipal(ctx context.Context, dbName string, principalID int64) (*UserModel, error) {
 	l := ctxzap.Extract(ctx)
 	l.Debug("getting server principal for database user")
 
-	var sb strings.Builder
-	_, _ = sb.WriteString(`
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	var sid []byte
+	err = conn.QueryRowxContext(ctx, fmt.Sprintf("SELECT sid FROM [%s].sys.database_principals WHERE principal_id = @p1", dbName), principalID).Scan(&sid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoServerPrincipal
+		}
+		return nil, err
+	}
+
+	row := c.db.QueryRowxContext(ctx, `
 SELECT
 	principal_id,
 	sid,
@@ -118,17 +218,13 @@ SELECT
 	is_disabled
 FROM
     sys.server_principals 
-WHERE sid = (SELECT sid FROM [`)
-	_, _ = sb.WriteString(dbName)
-	_, _ = sb.WriteString(`].sys.database_principals WHERE principal_id = @p1)`)
-
-	row := c.db.QueryRowxContext(ctx, sb.String(), principalID)
+WHERE sid = @p1`, sid)
 	if row.Err() != nil {
 		return nil, row.Err()
 	}
 
 	var ret UserModel
-	err := row.StructScan(&ret)
+	err = row.StructScan(&ret)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNoServerPrincipal
@@ -170,7 +266,12 @@ ORDER BY
   principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
 `)
 
-	rows, err := c.db.QueryxContext(ctx, sb.String(), args...)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
 	if err != nil {
 		return nil, "", err
 	}
@@ -199,27 +300,67 @@ ORDER BY
 	return ret, nextPageToken, nil
 }
 
+// GetDatabaseUserPrincipal returns the database user with the given database principal ID.
+func (c *Client) GetDatabaseUserPrincipal(ctx context.Context, dbName string, principalID string) (*UserModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting database user principal", zap.String("dbName", dbName), zap.String("principal_id", principalID))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`
+SELECT 
+  principal_id,
+  name, 
+  type_desc
+FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals
+WHERE 
+  (
+    type = 'S' 
+    OR type = 'U' 
+    OR type = 'C' 
+    or type = 'E' 
+    or type = 'K'
+  ) 
+  AND principal_id = @p1
+`)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, sb.String(), principalID)
+	if row.Err() != nil {
+		return nil, row.Err()
+	}
+
+	var ret UserModel
+	err = row.StructScan(&ret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
 func (c *Client) GetUserPrincipal(ctx context.Context, userId string) (*UserModel, error) {
 	l := ctxzap.Extract(ctx)
 	l.Debug("getting user")
 
 	query := `
-SELECT
-    principal_id,
-    sid,
-    name,
-    type_desc,
-    is_disabled
-FROM
-    sys.server_principals
-WHERE
+SELECT` + c.userLoginColumns() + `FROM` + loginTables + `WHERE
     (
-		type = 'S'
-		OR type = 'U'
-		OR type = 'C'
-		OR type = 'E'
-		OR type = 'K'
-	) AND principal_id = @p1
+		sp.type = 'S'
+		OR sp.type = 'U'
+		OR sp.type = 'C'
+		OR sp.type = 'E'
+		OR sp.type = 'K'
+	) AND sp.principal_id = @p1
 `
 
 	rows := c.db.QueryRowxContext(ctx, query, userId)
@@ -236,6 +377,11 @@ WHERE
 		return nil, err
 	}
 
+	err = c.checkPrincipalAllowed(userModel.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	return &userModel, nil
 }
 
@@ -276,10 +422,16 @@ WHERE
 		return nil, err
 	}
 
+	err = c.checkPrincipalAllowed(userModel.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	return &userModel, nil
 }
 
 // GetUserFromDb find db user from Server principal.
+// The SID of the login is read first, so the server and database catalogs can be on different connections.
 func (c *Client) GetUserFromDb(ctx context.Context, db, principalId string) (*UserDBModel, error) {
 	l := ctxzap.Extract(ctx)
 	l.Debug("getting user")
@@ -288,11 +440,20 @@ func (c *Client) GetUserFromDb(ctx context.Context, db, principalId string) (*Us
 		return nil, fmt.Errorf("invalid characters in dbName")
 	}
 
+	var sid []byte
+	err := c.db.QueryRowxContext(ctx, "SELECT sid FROM sys.server_principals WHERE principal_id = @p1", principalId).Scan(&sid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			l.Info("user not found for principal", zap.String("principalId", principalId))
+			return nil, nil
+		}
+		return nil, err
+	}
+
 	query := `
 USE [%s];
 SELECT
     dp.principal_id AS principal_id,
-    sp.principal_id AS database_principal_id,
 	dp.sid AS sid,
 	dp.name as name,
 	dp.type_desc AS type_desc,
@@ -300,22 +461,25 @@ SELECT
 	dp.modify_date AS modify_date,
 	dp.owning_principal_id as owning_principal_id
 FROM sys.database_principals dp
-LEFT JOIN sys.server_principals sp
-ON dp.sid = sp.sid
-WHERE dp.type IN ('S', 'U')
+WHERE dp.type IN ('S', 'U', 'G', 'E', 'X')
 AND dp.name NOT IN ('dbo', 'guest', 'INFORMATION_SCHEMA', 'sys')
-AND sp.principal_id = @p1
+AND dp.sid = @p1
 `
 
 	query = fmt.Sprintf(query, db)
 
-	row := c.db.QueryRowxContext(ctx, query, principalId)
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, query, sid)
 	if err := row.Err(); err != nil {
 		return nil, err
 	}
 
 	var userModel UserDBModel
-	err := row.StructScan(&userModel)
+	err = row.StructScan(&userModel)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			l.Info("user not found for principal", zap.String("principalId", principalId))
@@ -323,6 +487,7 @@ AND sp.principal_id = @p1
 		}
 		return nil, err
 	}
+	userModel.DatabasePrincipalId = principalId
 
 	return &userModel, nil
 }
@@ -344,8 +509,42 @@ CREATE USER [%s] FOR LOGIN [%s];
 
 	l.Debug("SQL QUERY", zap.String("q", query))
 
-	_, err := c.db.ExecContext(ctx, query)
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, query)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SQLLoginTypeDesc is the sys.server_principals type_desc of logins that authenticate with a password.
+const SQLLoginTypeDesc = "SQL_LOGIN"
+
+// SetDatabaseUserDefaultSchema sets the schema that unqualified object names of the database user resolve to.
+func (c *Client) SetDatabaseUserDefaultSchema(ctx context.Context, db, user, schema string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("setting database user default schema", zap.String("db", db), zap.String("user", user), zap.String("schema", schema))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(user, "[]\"';") || strings.ContainsAny(schema, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, user or schema")
+	}
+
+	command := fmt.Sprintf("USE [%s]; ALTER USER [%s] WITH DEFAULT_SCHEMA = [%s];", db, user, schema)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
 
+	_, err = conn.ExecContext(ctx, command)
 	if err != nil {
 		return err
 	}
@@ -365,56 +564,471 @@ const (
 	LoginTypeAzureAD LoginType = "AZURE_AD"
 	// LoginTypeEntraID represents Azure Entra ID authentication.
 	LoginTypeEntraID LoginType = "ENTRA_ID"
+	// LoginTypeCertificate represents a login mapped to a certificate in master, used for code signing.
+	LoginTypeCertificate LoginType = "CERTIFICATE"
+	// LoginTypeAsymmetricKey represents a login mapped to an asymmetric key in master, used for code signing.
+	LoginTypeAsymmetricKey LoginType = "ASYMMETRIC_KEY"
 )
 
+// LoginOptions holds the optional settings of a new login.
+// Unset options are left to the server defaults, e.g. master as the default database.
+type LoginOptions struct {
+	DefaultDatabase string
+	DefaultLanguage string
+	// CheckPolicy, CheckExpiration and MustChange only apply to SQL logins.
+	// MustChange requires both the policy and expiration checks, which are turned on with it.
+	CheckPolicy     *bool
+	CheckExpiration *bool
+	MustChange      bool
+	// Certificate and AsymmetricKey name the certificate or asymmetric key in master a login is mapped to.
+	Certificate   string
+	AsymmetricKey string
+}
+
+// withClause returns the WITH options of a CREATE LOGIN statement for the login type, or an error if an option does not apply to it.
+func (o *LoginOptions) withClause(loginType LoginType) ([]string, error) {
+	if o == nil {
+		return nil, nil
+	}
+
+	if strings.ContainsAny(o.DefaultDatabase, "[]\"';") || strings.ContainsAny(o.DefaultLanguage, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in default database or default language")
+	}
+
+	var ret []string
+	if o.DefaultDatabase != "" {
+		ret = append(ret, fmt.Sprintf("DEFAULT_DATABASE = [%s]", o.DefaultDatabase))
+	}
+	if o.DefaultLanguage != "" {
+		ret = append(ret, fmt.Sprintf("DEFAULT_LANGUAGE = [%s]", o.DefaultLanguage))
+	}
+
+	hasPasswordOptions := o.CheckPolicy != nil || o.CheckExpiration != nil || o.MustChange
+	switch loginType {
+	case LoginTypeSQL:
+		checkPolicy, checkExpiration := o.CheckPolicy, o.CheckExpiration
+		if o.MustChange {
+			if (checkPolicy != nil && !*checkPolicy) || (checkExpiration != nil && !*checkExpiration) {
+				return nil, fmt.Errorf("must change requires the password policy and expiration checks")
+			}
+			on := true
+			checkPolicy, checkExpiration = &on, &on
+		}
+		if checkPolicy != nil {
+			ret = append(ret, fmt.Sprintf("CHECK_POLICY = %s", onOff(*checkPolicy)))
+		}
+		if checkExpiration != nil {
+			ret = append(ret, fmt.Sprintf("CHECK_EXPIRATION = %s", onOff(*checkExpiration)))
+		}
+
+	case LoginTypeWindows:
+		if hasPasswordOptions {
+			return nil, fmt.Errorf("password options only apply to SQL logins")
+		}
+
+	default:
+		if hasPasswordOptions || len(ret) > 0 {
+			return nil, fmt.Errorf("login options are not supported for %s logins", loginType)
+		}
+	}
+
+	return ret, nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
+
 // CreateLogin creates a SQL Server login with the specified authentication type.
-// For Windows authentication (loginType=WINDOWS):
-//   - If domain is provided, it will create the login in the format [DOMAIN\Username]
-//   - otherwise it will use just [Username]
-//
-// For SQL authentication (loginType=SQL):
-//   - It requires a password
-//   - Domain is ignored
-//
-// For Azure AD authentication (loginType=AZURE_AD):
-//   - It creates from EXTERNAL PROVIDER
-//   - Username should be the full Azure AD username/email
-//
-// For Entra ID authentication (loginType=ENTRA_ID):
-//   - It creates from EXTERNAL PROVIDER
-//   - Username should be the full Entra ID username/email
-func (c *Client) CreateLogin(ctx context.Context, loginType LoginType, username, password string) error {
+func (c *Client) CreateLogin(ctx context.Context, loginType LoginType, username, password string, opts *LoginOptions) error {
 	l := ctxzap.Extract(ctx)
 
+	if strings.ContainsAny(username, "[]\"';") {
+		return fmt.Errorf("invalid characters in username")
+	}
+
+	options, err := opts.withClause(loginType)
+	if err != nil {
+		return err
+	}
+
+	loginName := fmt.Sprintf("[%s]", username)
+
 	var query string
 	switch loginType {
 	case LoginTypeWindows:
-		loginName := fmt.Sprintf("[%s]", username)
 		l.Debug("creating windows login", zap.String("login", loginName))
-		query = fmt.Sprintf("CREATE LOGIN %s FROM WINDOWS;", loginName)
+		query = fmt.Sprintf("CREATE LOGIN %s FROM WINDOWS", loginName)
+		if len(options) > 0 {
+			query += " WITH " + strings.Join(options, ", ")
+		}
 	case LoginTypeSQL:
-		if password == "" {
+		if password == "" || strings.Contains(password, "'") {
 			return fmt.Errorf("password is required for SQL Server authentication")
 		}
-		// For SQL Server authentication, only username and password are used
-		loginName := fmt.Sprintf("[%s]", username)
 		l.Debug("creating SQL login", zap.String("login", loginName))
-		query = fmt.Sprintf("CREATE LOGIN %s WITH PASSWORD = '%s';", loginName, password)
+		query = fmt.Sprintf("CREATE LOGIN %s WITH PASSWORD = '%s'", loginName, password)
+		if opts != nil && opts.MustChange {
+			query += " MUST_CHANGE"
+		}
+		if len(options) > 0 {
+			query += ", " + strings.Join(options, ", ")
+		}
 	case LoginTypeAzureAD, LoginTypeEntraID:
 		// Azure AD and Entra ID use external provider
-		loginName := fmt.Sprintf("[%s]", username)
 		l.Debug("creating external provider login", zap.String("login", loginName), zap.String("type", string(loginType)))
-		query = fmt.Sprintf("CREATE LOGIN %s FROM EXTERNAL PROVIDER;", loginName)
+		query = fmt.Sprintf("CREATE LOGIN %s FROM EXTERNAL PROVIDER", loginName)
+	case LoginTypeCertificate:
+		if opts == nil || opts.Certificate == "" || strings.ContainsAny(opts.Certificate, "[]\"';") {
+			return fmt.Errorf("a valid certificate name is required for certificate logins")
+		}
+		l.Debug("creating certificate login", zap.String("login", loginName), zap.String("certificate", opts.Certificate))
+		query = fmt.Sprintf("CREATE LOGIN %s FROM CERTIFICATE [%s]", loginName, opts.Certificate)
+	case LoginTypeAsymmetricKey:
+		if opts == nil || opts.AsymmetricKey == "" || strings.ContainsAny(opts.AsymmetricKey, "[]\"';") {
+			return fmt.Errorf("a valid asymmetric key name is required for asymmetric key logins")
+		}
+		l.Debug("creating asymmetric key login", zap.String("login", loginName), zap.String("asymmetric_key", opts.AsymmetricKey))
+		query = fmt.Sprintf("CREATE LOGIN %s FROM ASYMMETRIC KEY [%s]", loginName, opts.AsymmetricKey)
 	default:
 		return fmt.Errorf("unsupported login type: %s", loginType)
 	}
+	query += ";"
 
-	l.Debug("SQL QUERY", zap.String("q", query))
+	// The query of SQL logins holds the password and is not logged.
+	if loginType != LoginTypeSQL {
+		l.Debug("SQL QUERY", zap.String("q", query))
+	}
 
-	_, err := c.db.ExecContext(ctx, query)
+	_, err = c.db.ExecContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to create login: %w", err)
 	}
 
 	return nil
 }
+
+// DatabaseUserModel is a user of a database.
+type DatabaseUserModel struct {
+	ID                 int64  `db:"principal_id"`
+	Name               string `db:"name"`
+	Type               string `db:"type"`
+	TypeDesc           string `db:"type_desc"`
+	AuthenticationType string `db:"authentication_type_desc"`
+	DefaultSchema      string `db:"default_schema_name"`
+	// IsOrphaned is set for users that were created for a SQL login that no longer exists on the server,
+	// typically after the database was restored or attached on another server.
+	IsOrphaned bool `db:"is_orphaned"`
+	// LoginName is the server login the user is mapped to, if any. It is always empty on Azure SQL Database,
+	// where the logins are not visible from user databases.
+	LoginName string `db:"login_name"`
+}
+
+// databaseUserColumns are the sys.database_principals columns selected into a DatabaseUserModel.
+// A user is orphaned when it authenticates through a login (INSTANCE) and no server principal has its SID.
+const databaseUserColumns = `
+  dp.principal_id,
+  dp.name,
+  dp.type,
+  dp.type_desc,
+  dp.authentication_type_desc,
+  ISNULL(dp.default_schema_name, '') AS default_schema_name,
+  CAST(CASE WHEN dp.authentication_type_desc = 'INSTANCE' AND sp.principal_id IS NULL THEN 1 ELSE 0 END AS bit) AS is_orphaned,
+  ISNULL(sp.name, '') AS login_name
+`
+
+// serverPrincipalJoin joins the server principal with the SID of the database principal dp as sp.
+// Azure SQL Database only shows logins in master, so there users that authenticate through a login are assumed
+// to have one, and orphaned users are not detected.
+func (c *Client) serverPrincipalJoin() string {
+	if c.azureSQLDatabase {
+		return "OUTER APPLY (SELECT CASE WHEN dp.authentication_type_desc = 'INSTANCE' THEN dp.principal_id END AS principal_id, CAST(NULL AS sysname) AS name) sp"
+	}
+	return "LEFT JOIN sys.server_principals sp ON sp.sid = dp.sid"
+}
+
+// ListDatabaseUsers returns the users of a database, both the users mapped to a server login and the users without one:
+// contained users, users created WITHOUT LOGIN, users created FROM EXTERNAL PROVIDER and users whose login was dropped.
+func (c *Client) ListDatabaseUsers(ctx context.Context, dbName string, pager *Pager) ([]*DatabaseUserModel, string, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("listing database users", zap.String("dbName", dbName))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, "", fmt.Errorf("invalid characters in dbName")
+	}
+
+	offset, limit, err := pager.Parse()
+	if err != nil {
+		return nil, "", err
+	}
+	args := []interface{}{offset, limit + 1}
+
+	var sb strings.Builder
+	// guest, INFORMATION_SCHEMA and sys are built-in users without a login and are skipped.
+	// https://learn.microsoft.com/en-us/sql/relational-databases/system-catalog-views/sys-database-principals-transact-sql
+	_, _ = sb.WriteString(`
+SELECT`)
+	_, _ = sb.WriteString(databaseUserColumns)
+	_, _ = sb.WriteString(`FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals dp
+`)
+	_, _ = sb.WriteString(c.serverPrincipalJoin())
+	_, _ = sb.WriteString(`
+WHERE dp.type IN ('S', 'U', 'G', 'E', 'X', 'C', 'K')
+  AND dp.name NOT IN ('guest', 'INFORMATION_SCHEMA', 'sys')
+ORDER BY
+  dp.principal_id ASC OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY
+`)
+	l.Debug("ListDatabaseUsers",
+		zap.String("sql query", sb.String()),
+		zap.Any("args", args),
+	)
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := conn.QueryxContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var ret []*DatabaseUserModel
+	for rows.Next() {
+		var userModel DatabaseUserModel
+		err = rows.StructScan(&userModel)
+		if err != nil {
+			return nil, "", err
+		}
+		ret = append(ret, &userModel)
+	}
+	if rows.Err() != nil {
+		return nil, "", rows.Err()
+	}
+
+	var nextPageToken string
+	if len(ret) > limit {
+		offset += limit
+		nextPageToken = strconv.Itoa(offset)
+		ret = ret[:limit]
+	}
+
+	return ret, nextPageToken, nil
+}
+
+// GetDatabaseUserByName returns the user of a database with the given name, or nil if there is no such user.
+func (c *Client) GetDatabaseUserByName(ctx context.Context, dbName string, name string) (*DatabaseUserModel, error) {
+	l := ctxzap.Extract(ctx)
+	l.Debug("getting database user by name", zap.String("dbName", dbName), zap.String("name", name))
+
+	if strings.ContainsAny(dbName, "[]\"';") {
+		return nil, fmt.Errorf("invalid characters in dbName")
+	}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(`
+SELECT`)
+	_, _ = sb.WriteString(databaseUserColumns)
+	_, _ = sb.WriteString(`FROM [`)
+	_, _ = sb.WriteString(dbName)
+	_, _ = sb.WriteString(`].sys.database_principals dp
+`)
+	_, _ = sb.WriteString(c.serverPrincipalJoin())
+	_, _ = sb.WriteString(`
+WHERE dp.type IN ('S', 'U', 'G', 'E', 'X', 'C', 'K')
+  AND dp.name = @p1
+`)
+
+	conn, err := c.databaseConn(ctx, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn.QueryRowxContext(ctx, sb.String(), name)
+	if err := row.Err(); err != nil {
+		return nil, err
+	}
+
+	var userModel DatabaseUserModel
+	err = row.StructScan(&userModel)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &userModel, nil
+}
+
+// RemapDatabaseUser maps an existing database user to a login, keeping the permissions and role memberships of the user.
+func (c *Client) RemapDatabaseUser(ctx context.Context, db, user, login string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("remapping database user", zap.String("db", db), zap.String("user", user), zap.String("login", login))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(user, "[]\"';") || strings.ContainsAny(login, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName, user or login")
+	}
+
+	command := fmt.Sprintf("USE [%s]; ALTER USER [%s] WITH LOGIN = [%s];", db, user, login)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DropDatabaseUser drops a user from a database.
+func (c *Client) DropDatabaseUser(ctx context.Context, db, user string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("dropping database user", zap.String("db", db), zap.String("user", user))
+
+	if strings.ContainsAny(db, "[]\"';") || strings.ContainsAny(user, "[]\"';") {
+		return fmt.Errorf("invalid characters in dbName or user")
+	}
+
+	command := fmt.Sprintf("USE [%s]; DROP USER [%s];", db, user)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	conn, err := c.databaseConn(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetLoginDisabled disables or enables a login. A disabled login keeps its users, permissions and role memberships.
+func (c *Client) SetLoginDisabled(ctx context.Context, login string, disabled bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("setting login status", zap.String("login", login), zap.Bool("disabled", disabled))
+
+	if strings.ContainsAny(login, "[]\"';") {
+		return fmt.Errorf("invalid characters in login")
+	}
+
+	status := "ENABLE"
+	if disabled {
+		status = "DISABLE"
+	}
+	command := fmt.Sprintf("ALTER LOGIN [%s] %s;", login, status)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnlockLogin unlocks a SQL login locked out by the password policy without changing its password.
+// Turning the policy check off and on again resets the lockout, the expiration check is restored as it was.
+func (c *Client) UnlockLogin(ctx context.Context, login string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("unlocking login", zap.String("login", login))
+
+	if strings.ContainsAny(login, "[]\"';") {
+		return fmt.Errorf("invalid characters in login")
+	}
+
+	var isExpirationChecked bool
+	err := c.db.QueryRowxContext(ctx, "SELECT is_expiration_checked FROM sys.sql_logins WHERE name = @p1", login).Scan(&isExpirationChecked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s is not a SQL login", login)
+		}
+		return err
+	}
+
+	expiration := "OFF"
+	if isExpirationChecked {
+		expiration = "ON"
+	}
+	command := fmt.Sprintf(
+		"ALTER LOGIN [%s] WITH CHECK_EXPIRATION = OFF, CHECK_POLICY = OFF; ALTER LOGIN [%s] WITH CHECK_POLICY = ON, CHECK_EXPIRATION = %s;",
+		login,
+		login,
+		expiration,
+	)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err = c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameLogin renames a login. The database users mapped to the login keep their names.
+func (c *Client) RenameLogin(ctx context.Context, login string, newName string) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("renaming login", zap.String("login", login), zap.String("new_name", newName))
+
+	if strings.ContainsAny(login, "[]\"';") || strings.ContainsAny(newName, "[]\"';") {
+		return fmt.Errorf("invalid characters in login or new name")
+	}
+
+	command := fmt.Sprintf("ALTER LOGIN [%s] WITH NAME = [%s];", login, newName)
+
+	l.Debug("SQL QUERY", zap.String("q", command))
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetLoginPassword sets the password of a SQL login. With mustChange, the login has to change the password at its next login,
+// which requires the password policy and expiration checks to be on.
+func (c *Client) SetLoginPassword(ctx context.Context, login string, password string, mustChange bool) error {
+	l := ctxzap.Extract(ctx)
+	l.Debug("setting login password", zap.String("login", login), zap.Bool("must_change", mustChange))
+
+	if strings.ContainsAny(login, "[]\"';") {
+		return fmt.Errorf("invalid characters in login")
+	}
+
+	if password == "" || strings.Contains(password, "'") {
+		return fmt.Errorf("invalid login password")
+	}
+
+	// The command holds the password and is not logged.
+	command := fmt.Sprintf("ALTER LOGIN [%s] WITH PASSWORD = '%s'", login, password)
+	if mustChange {
+		command += " MUST_CHANGE, CHECK_EXPIRATION = ON, CHECK_POLICY = ON"
+	}
+	command += ";"
+
+	_, err := c.db.ExecContext(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -22,3 +22,35 @@ func TestClient_GetServerPrincipalForDatabaseUser(t *testing.T) {
 	require.Error(t, err)
 	require.ErrorIs(t, err, ErrNoServerPrincipal)
 }
+
+func TestLoginOptionsWithClause(t *testing.T) {
+	on, off := true, false
+
+	options, err := (*LoginOptions)(nil).withClause(LoginTypeSQL)
+	require.NoError(t, err)
+	require.Empty(t, options)
+
+	options, err = (&LoginOptions{DefaultDatabase: "app", CheckPolicy: &on, CheckExpiration: &off}).withClause(LoginTypeSQL)
+	require.NoError(t, err)
+	require.Equal(t, []string{"DEFAULT_DATABASE = [app]", "CHECK_POLICY = ON", "CHECK_EXPIRATION = OFF"}, options)
+
+	options, err = (&LoginOptions{MustChange: true}).withClause(LoginTypeSQL)
+	require.NoError(t, err)
+	require.Equal(t, []string{"CHECK_POLICY = ON", "CHECK_EXPIRATION = ON"}, options)
+
+	_, err = (&LoginOptions{MustChange: true, CheckExpiration: &off}).withClause(LoginTypeSQL)
+	require.Error(t, err)
+
+	options, err = (&LoginOptions{DefaultLanguage: "us_english"}).withClause(LoginTypeWindows)
+	require.NoError(t, err)
+	require.Equal(t, []string{"DEFAULT_LANGUAGE = [us_english]"}, options)
+
+	_, err = (&LoginOptions{CheckPolicy: &on}).withClause(LoginTypeWindows)
+	require.Error(t, err)
+
+	_, err = (&LoginOptions{DefaultDatabase: "app"}).withClause(LoginTypeCertificate)
+	require.Error(t, err)
+
+	_, err = (&LoginOptions{DefaultDatabase: "app]; DROP LOGIN [sa"}).withClause(LoginTypeSQL)
+	require.Error(t, err)
+}